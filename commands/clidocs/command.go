@@ -0,0 +1,149 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+// Package clidocs
+// generate reference documents of console application itself, such
+// as roff man pages, markdown pages and json description.
+//
+//   ./demo cli-docs --dir=./docs/cli
+//   ./demo cli-docs --format=man --dir=/usr/local/share/man/man1
+//   ./demo cli-docs --format=json
+package clidocs
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fuyibing/console/v3/managers"
+	"os"
+	"path/filepath"
+)
+
+const (
+	CmdDesc = "Generate man pages, markdown pages and json description of commands"
+	CmdName = "cli-docs"
+
+	FormatAll      = "all"
+	FormatJson     = "json"
+	FormatMan      = "man"
+	FormatMarkdown = "markdown"
+)
+
+type (
+	// Command
+	// for console application documents.
+	Command struct {
+		Command managers.Command
+		Err     error
+		Name    string
+		Params  *Params
+	}
+
+	// Params
+	// bound with command options.
+	Params struct {
+		Dir     string `console:"dir,d" desc:"Generated documents storage location" default:"./docs/cli"`
+		Format  string `console:"format,f" desc:"Document format" default:"all" enum:"all,man,markdown,json"`
+		Program string `console:"program,p" desc:"Program name used in documents, default is name of binary"`
+	}
+)
+
+// Handle
+// describe manager and write documents.
+func (o *Command) Handle(ctx context.Context, m managers.Manager, a managers.Arguments) (err error) {
+	var (
+		buf   []byte
+		d     *Document
+		files = make(map[string]string)
+		p     = &Params{}
+		res   = make(map[string]interface{})
+	)
+
+	// Read options
+	// of invocation.
+	if err = a.Populate(p); err != nil {
+		return
+	}
+	if p.Program == "" {
+		p.Program = filepath.Base(os.Args[0])
+	}
+
+	// Describe
+	// commands of manager.
+	d = Describe(m, p.Program)
+
+	// Render pages
+	// of format.
+	switch p.Format {
+	case FormatAll, FormatJson, FormatMan, FormatMarkdown:
+	default:
+		return managers.NewValidationError("unknown format: %s", p.Format)
+	}
+	if p.Format == FormatAll || p.Format == FormatJson {
+		if buf, err = json.MarshalIndent(d, "", "    "); err != nil {
+			return
+		}
+		files[p.Program+".json"] = string(buf)
+	}
+	if p.Format == FormatAll || p.Format == FormatMan {
+		for k, v := range ManPages(d) {
+			files[filepath.Join("man", k)] = v
+		}
+	}
+	if p.Format == FormatAll || p.Format == FormatMarkdown {
+		for k, v := range MarkdownPages(d) {
+			files[filepath.Join("markdown", k)] = v
+		}
+	}
+
+	// Write files.
+	for k, v := range files {
+		// Stop
+		// if context cancelled.
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		fullPath := filepath.Join(p.Dir, k)
+		if err = os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err == nil {
+			err = os.WriteFile(fullPath, []byte(v), 0644)
+		}
+		if err != nil {
+			res[fullPath] = err
+			break
+		}
+		res[fullPath] = "succeed"
+	}
+
+	a.GetOutput().Map(res, "Console documents generated")
+	return
+}
+
+// InitField
+// initialize command fields.
+func (o *Command) InitField() *Command {
+	o.Command = managers.NewCommand(o.Name)
+	o.Command.SetDescription(CmdDesc).SetContextHandler(o.Handle)
+	return o
+}
+
+// InitOption
+// initialize command option.
+func (o *Command) InitOption() *Command {
+	o.Err = o.Command.BindStruct(o.Params)
+	return o
+}
+
+// New
+// create and return instance.
+//
+//   go run main.go cli-docs \
+//     --dir=./docs/cli \
+//     --format=markdown \
+//     --program=demo
+func New() (managers.Command, error) {
+	o := (&Command{Name: CmdName, Params: &Params{}}).
+		InitField().
+		InitOption()
+
+	return o.Command, o.Err
+}
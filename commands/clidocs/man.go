@@ -0,0 +1,153 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package clidocs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ManName
+// return man page name of command path, index page if path is empty.
+//
+//   consul kv download -> demo-consul-kv-download
+func ManName(program, path string) string {
+	return strings.Join(append([]string{program}, strings.Fields(path)...), "-")
+}
+
+// ManPages
+// render roff man pages of document, key is file name and value
+// is page content. Index page is named as program.
+//
+//   demo.1
+//   demo-consul-kv-download.1
+func ManPages(d *Document) map[string]string {
+	var (
+		date  = time.Now().Format("Jan 2006")
+		pages = make(map[string]string)
+	)
+
+	// Index page.
+	b := &strings.Builder{}
+	manHeader(b, d, d.Program, date)
+	manSection(b, "NAME")
+	manLine(b, "%s \\- %s", manEscape(d.Program), manEscape(d.Description))
+	manSection(b, "SYNOPSIS")
+	manLine(b, "\\fB%s\\fR COMMAND [OPTIONS]", manEscape(d.Program))
+	if d.Description != "" {
+		manSection(b, "DESCRIPTION")
+		manLine(b, "%s", manEscape(d.Description))
+	}
+	manSection(b, "COMMANDS")
+	for _, c := range d.Commands {
+		manLine(b, ".TP")
+		manLine(b, "\\fB%s\\fR", manEscape(c.Path))
+		manLine(b, "%s", manEscape(c.Description))
+	}
+	manSeeAlso(b, d, d.top())
+	pages[d.Program+".1"] = b.String()
+
+	// Command pages.
+	for _, c := range d.Commands {
+		b = &strings.Builder{}
+		name := ManName(d.Program, c.Path)
+
+		manHeader(b, d, name, date)
+		manSection(b, "NAME")
+		manLine(b, "%s \\- %s", manEscape(name), manEscape(c.Description))
+		manSection(b, "SYNOPSIS")
+		manLine(b, "\\fB%s\\fR", manEscape(c.Usage))
+		manSection(b, "DESCRIPTION")
+		manLine(b, "%s", manEscape(c.Description))
+
+		// Aliases.
+		if len(c.Aliases) > 0 {
+			manSection(b, "ALIASES")
+			manLine(b, "%s", manEscape(strings.Join(c.Aliases, ", ")))
+		}
+
+		// Positional arguments.
+		if len(c.Positionals) > 0 {
+			manSection(b, "ARGUMENTS")
+			for _, p := range c.Positionals {
+				manLine(b, ".TP")
+				manLine(b, "\\fB%s\\fR", manEscape(p.Label))
+				manLine(b, "%s", manEscape(p.Description))
+			}
+		}
+
+		// Options.
+		manOptions(b, "OPTIONS", c.Options)
+		manOptions(b, "GLOBAL OPTIONS", c.Globals)
+
+		// Children
+		// of command group.
+		list := make([]*CommandDoc, 0)
+		if c.Parent != "" {
+			list = append(list, d.lookup(c.Parent))
+		}
+		for _, k := range c.Commands {
+			list = append(list, d.lookup(k))
+		}
+		manSeeAlso(b, d, list)
+		pages[name+".1"] = b.String()
+	}
+	return pages
+}
+
+// Escape text
+// for roff.
+func manEscape(s string) string {
+	s = strings.NewReplacer("\\", "\\e", "-", "\\-").Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
+
+func manHeader(b *strings.Builder, d *Document, name, date string) {
+	manLine(b, `.TH "%s" "1" "%s" "%s %s" "%s Manual"`, strings.ToUpper(name), date, d.Program, d.Version, d.Program)
+	manLine(b, ".nh")
+	manLine(b, ".ad l")
+}
+
+func manLine(b *strings.Builder, format string, args ...interface{}) {
+	b.WriteString(fmt.Sprintf(format, args...))
+	b.WriteString("\n")
+}
+
+func manOptions(b *strings.Builder, name string, list []*OptionDoc) {
+	if len(list) == 0 {
+		return
+	}
+
+	manSection(b, name)
+	for _, opt := range list {
+		manLine(b, ".TP")
+		manLine(b, "\\fB%s\\fR", manEscape(opt.Label))
+		text := optionText(opt)
+		if opt.Default != nil {
+			text = fmt.Sprintf("%s (default: %v)", text, opt.Default)
+		}
+		manLine(b, "%s", manEscape(text))
+	}
+}
+
+func manSection(b *strings.Builder, name string) {
+	manLine(b, ".SH %s", name)
+}
+
+// See also
+// related pages, index page is always included.
+func manSeeAlso(b *strings.Builder, d *Document, list []*CommandDoc) {
+	ss := []string{fmt.Sprintf("\\fB%s\\fR(1)", manEscape(d.Program))}
+	for _, c := range list {
+		if c != nil {
+			ss = append(ss, fmt.Sprintf("\\fB%s\\fR(1)", manEscape(ManName(d.Program, c.Path))))
+		}
+	}
+	manSection(b, "SEE ALSO")
+	manLine(b, "%s", strings.Join(ss, ", "))
+}
@@ -0,0 +1,162 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package clidocs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarkdownName
+// return markdown page name of command path, index page if path
+// is empty.
+//
+//   consul kv download -> demo_consul_kv_download
+func MarkdownName(program, path string) string {
+	return strings.Join(append([]string{program}, strings.Fields(path)...), "_")
+}
+
+// MarkdownPages
+// render markdown pages of document, key is file name and value
+// is page content. Index page is named as program.
+//
+//   demo.md
+//   demo_consul_kv_download.md
+func MarkdownPages(d *Document) map[string]string {
+	pages := make(map[string]string)
+
+	// Index page.
+	b := &strings.Builder{}
+	mdLine(b, "# %s", d.Program)
+	mdLine(b, "")
+	if d.Description != "" {
+		mdLine(b, "%s", d.Description)
+		mdLine(b, "")
+	}
+	mdLine(b, "Version: %s", d.Version)
+	mdLine(b, "")
+	mdLine(b, "## Usage")
+	mdLine(b, "")
+	mdLine(b, "```")
+	mdLine(b, "%s COMMAND [OPTIONS]", d.Program)
+	mdLine(b, "```")
+	mdLine(b, "")
+	mdLine(b, "## Commands")
+	mdLine(b, "")
+	for _, c := range d.Commands {
+		mdLine(b, "* [%s %s](%s.md) - %s", d.Program, c.Path, MarkdownName(d.Program, c.Path), c.Description)
+	}
+	pages[d.Program+".md"] = b.String()
+
+	// Command pages.
+	for _, c := range d.Commands {
+		b = &strings.Builder{}
+
+		mdLine(b, "# %s %s", d.Program, c.Path)
+		mdLine(b, "")
+		mdLine(b, "%s", c.Description)
+		mdLine(b, "")
+		mdLine(b, "## Usage")
+		mdLine(b, "")
+		mdLine(b, "```")
+		mdLine(b, "%s", c.Usage)
+		mdLine(b, "```")
+		mdLine(b, "")
+
+		// Aliases.
+		if len(c.Aliases) > 0 {
+			mdLine(b, "Aliases: %s", strings.Join(c.Aliases, ", "))
+			mdLine(b, "")
+		}
+
+		// Positional arguments.
+		if len(c.Positionals) > 0 {
+			mdLine(b, "## Arguments")
+			mdLine(b, "")
+			mdLine(b, "| Argument | Type | Mode | Description |")
+			mdLine(b, "|----------|------|------|-------------|")
+			for _, p := range c.Positionals {
+				mdLine(b, "| `%s` | %s | %s | %s |", p.Label, p.Type, p.Mode, mdEscape(p.Description))
+			}
+			mdLine(b, "")
+		}
+
+		// Options.
+		mdOptions(b, "Options", c.Options)
+		mdOptions(b, "Global Options", c.Globals)
+
+		// Children
+		// of command group.
+		if len(c.Commands) > 0 {
+			mdLine(b, "## Commands")
+			mdLine(b, "")
+			for _, k := range c.Commands {
+				if x := d.lookup(k); x != nil {
+					mdLine(b, "* [%s %s](%s.md) - %s", d.Program, x.Path, MarkdownName(d.Program, x.Path), x.Description)
+				}
+			}
+			mdLine(b, "")
+		}
+
+		// See also.
+		mdLine(b, "## See also")
+		mdLine(b, "")
+		if c.Parent != "" {
+			mdLine(b, "* [%s %s](%s.md)", d.Program, c.Parent, MarkdownName(d.Program, c.Parent))
+		}
+		mdLine(b, "* [%s](%s.md)", d.Program, d.Program)
+		pages[MarkdownName(d.Program, c.Path)+".md"] = b.String()
+	}
+	return pages
+}
+
+// Escape text
+// in table cell.
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+func mdLine(b *strings.Builder, format string, args ...interface{}) {
+	b.WriteString(fmt.Sprintf(format, args...))
+	b.WriteString("\n")
+}
+
+// Description
+// with fallback value sources.
+func mdOptions(b *strings.Builder, title string, list []*OptionDoc) {
+	if len(list) == 0 {
+		return
+	}
+
+	mdLine(b, "## %s", title)
+	mdLine(b, "")
+	mdLine(b, "| Option | Type | Default | Mode | Description |")
+	mdLine(b, "|--------|------|---------|------|-------------|")
+	for _, opt := range list {
+		def := ""
+		if opt.Default != nil {
+			def = fmt.Sprintf("`%v`", opt.Default)
+		}
+		name := "--" + opt.Name
+		if opt.ShortName != "" {
+			name = fmt.Sprintf("-%s, %s", opt.ShortName, name)
+		}
+		mdLine(b, "| `%s` | %s | %s | %s | %s |", name, opt.Type, def, opt.Mode, mdEscape(optionText(opt)))
+	}
+	mdLine(b, "")
+}
+
+func optionText(opt *OptionDoc) string {
+	ss := []string{opt.Description}
+	if len(opt.Enum) > 0 {
+		ss = append(ss, fmt.Sprintf("(accept: %s)", strings.Join(opt.Enum, ", ")))
+	}
+	if len(opt.Env) > 0 {
+		ss = append(ss, fmt.Sprintf("(env: %s)", strings.Join(opt.Env, ", ")))
+	}
+	if opt.ConfigKey != "" {
+		ss = append(ss, fmt.Sprintf("(config: %s)", opt.ConfigKey))
+	}
+	return strings.TrimSpace(strings.Join(ss, " "))
+}
@@ -0,0 +1,207 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package clidocs
+
+import (
+	"github.com/fuyibing/console/v3/managers"
+	"sort"
+	"strings"
+)
+
+type (
+	// Document
+	// description of manager and all visible commands.
+	Document struct {
+		Commands    []*CommandDoc `json:"commands"`
+		Description string        `json:"description"`
+		Program     string        `json:"program"`
+		Version     string        `json:"version"`
+	}
+
+	// CommandDoc
+	// description of command or command group.
+	CommandDoc struct {
+		Aliases     []string         `json:"aliases,omitempty"`
+		Commands    []string         `json:"commands,omitempty"`
+		Description string           `json:"description"`
+		Globals     []*OptionDoc     `json:"global_options,omitempty"`
+		Group       bool             `json:"group"`
+		Name        string           `json:"name"`
+		Options     []*OptionDoc     `json:"options,omitempty"`
+		Parent      string           `json:"parent,omitempty"`
+		Path        string           `json:"path"`
+		Positionals []*PositionalDoc `json:"positionals,omitempty"`
+		Usage       string           `json:"usage"`
+	}
+
+	// OptionDoc
+	// description of command option.
+	OptionDoc struct {
+		ConfigKey   string      `json:"config,omitempty"`
+		Default     interface{} `json:"default,omitempty"`
+		Description string      `json:"description"`
+		Enum        []string    `json:"enum,omitempty"`
+		Env         []string    `json:"env,omitempty"`
+		Label       string      `json:"label"`
+		Mode        string      `json:"mode"`
+		Name        string      `json:"name"`
+		Repeatable  bool        `json:"repeatable,omitempty"`
+		ShortName   string      `json:"short,omitempty"`
+		Type        string      `json:"type"`
+	}
+
+	// PositionalDoc
+	// description of positional argument.
+	PositionalDoc struct {
+		Description string `json:"description"`
+		Label       string `json:"label"`
+		Mode        string `json:"mode"`
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		Variadic    bool   `json:"variadic"`
+	}
+)
+
+var (
+	// ModeText
+	// names of option and positional modes.
+	ModeText = map[managers.Mode]string{
+		managers.ModeOptional: "optional",
+		managers.ModeRequired: "required",
+	}
+)
+
+// Describe
+// walk manager and return description of visible commands sorted
+// by path, hidden commands and their children are ignored.
+func Describe(m managers.Manager, program string) *Document {
+	d := &Document{
+		Commands:    make([]*CommandDoc, 0),
+		Description: m.GetDescription(),
+		Program:     program,
+		Version:     managers.Version,
+	}
+
+	var walk func(cs map[string]managers.Command)
+	walk = func(cs map[string]managers.Command) {
+		for _, c := range cs {
+			if !c.GetHidden() {
+				d.Commands = append(d.Commands, describeCommand(m, c, program))
+				walk(c.GetCommands())
+			}
+		}
+	}
+	walk(m.GetCommands())
+
+	sort.Slice(d.Commands, func(i, j int) bool {
+		return d.Commands[i].Path < d.Commands[j].Path
+	})
+	return d
+}
+
+// Top level
+// commands of document.
+func (o *Document) top() []*CommandDoc {
+	list := make([]*CommandDoc, 0)
+	for _, c := range o.Commands {
+		if c.Parent == "" {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Command
+// by path.
+func (o *Document) lookup(path string) *CommandDoc {
+	for _, c := range o.Commands {
+		if c.Path == path {
+			return c
+		}
+	}
+	return nil
+}
+
+func describeCommand(m managers.Manager, c managers.Command, program string) *CommandDoc {
+	var (
+		d = &CommandDoc{
+			Aliases:     c.GetAliases(),
+			Description: c.GetDescription(),
+			Group:       c.IsGroup(),
+			Name:        c.GetName(),
+			Path:        c.GetPath(),
+		}
+		names = make([]string, 0)
+		usage = []string{program, c.GetPath()}
+	)
+
+	if p := c.GetParent(); p != nil {
+		d.Parent = p.GetPath()
+	}
+
+	// Children
+	// of command group.
+	for _, x := range c.GetCommands() {
+		if !x.GetHidden() {
+			d.Commands = append(d.Commands, x.GetPath())
+		}
+	}
+	sort.Strings(d.Commands)
+
+	// Options
+	// sorted by name.
+	for k := range c.GetOptions() {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		d.Options = append(d.Options, describeOption(c.GetOptions()[k]))
+	}
+
+	// Inherited options
+	// of command groups and manager.
+	for _, opt := range m.GetInheritedOptions(c) {
+		d.Globals = append(d.Globals, describeOption(opt))
+	}
+
+	// Positional arguments
+	// in declared order.
+	for _, p := range c.GetPositionals() {
+		d.Positionals = append(d.Positionals, &PositionalDoc{
+			Description: p.GetDescription(),
+			Label:       p.GetLabel(),
+			Mode:        ModeText[p.GetMode()],
+			Name:        p.GetName(),
+			Type:        managers.ValueTypeText[p.GetValueType()],
+			Variadic:    p.IsVariadic(),
+		})
+	}
+
+	// Usage line.
+	if d.Group {
+		usage = append(usage, "COMMAND")
+	}
+	usage = append(usage, "[OPTIONS]")
+	for _, p := range d.Positionals {
+		usage = append(usage, p.Label)
+	}
+	d.Usage = strings.Join(usage, " ")
+	return d
+}
+
+func describeOption(opt managers.Option) *OptionDoc {
+	return &OptionDoc{
+		ConfigKey:   opt.GetConfigKey(),
+		Default:     opt.GetDefault(),
+		Description: opt.GetSummary(),
+		Enum:        opt.GetEnum(),
+		Env:         opt.GetEnv(),
+		Label:       strings.TrimSpace(opt.GetLabel()),
+		Mode:        ModeText[opt.GetMode()],
+		Name:        opt.GetName(),
+		Repeatable:  opt.IsRepeatable(),
+		ShortName:   opt.GetShortName(),
+		Type:        managers.ValueTypeText[opt.GetValueType()],
+	}
+}
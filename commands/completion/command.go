@@ -0,0 +1,109 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-18
+
+// Package completion
+// generate shell completion scripts for bash, zsh, fish and
+// powershell.
+//
+// # Load completion on bash
+//   source <(./demo completion bash)
+//
+// # Load completion on zsh
+//   ./demo completion zsh > "${fpath[1]}/_demo"
+//
+// # Load completion on fish
+//   ./demo completion fish | source
+//
+// # Load completion on powershell
+//   ./demo completion powershell | Out-String | Invoke-Expression
+package completion
+
+import (
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CmdDesc = "Generate shell completion script, accept: bash, zsh, fish, powershell"
+	CmdName = "completion"
+
+	ArgShell     = "shell"
+	ArgShellDesc = "Shell name, accept: bash, zsh, fish, powershell"
+
+	OptProgram     = "program"
+	OptProgramByte = 'p'
+	OptProgramDesc = "Program name of completion registered for"
+)
+
+type Command struct {
+	Command managers.Command
+	Err     error
+	Name    string
+}
+
+// Handle
+// callable registered on command manager interface.
+func (o *Command) Handle(_ managers.Manager, a managers.Arguments) (err error) {
+	var (
+		program string
+		shell   = a.GetPositional(ArgShell)
+		tpl     string
+		ok      bool
+	)
+
+	// Return error
+	// if shell not supported.
+	if tpl, ok = Scripts[shell]; !ok {
+		return managers.NewValidationError("unknown shell: %s", shell)
+	}
+
+	// Program name.
+	if program, err = a.GetOption(OptProgram).ToString(); err != nil {
+		return
+	}
+
+	// Print script.
+	_, err = fmt.Fprint(a.GetIO().Out, strings.NewReplacer(
+		"{{FUNC}}", Identifier(program),
+		"{{PROGRAM}}", program,
+		"{{COMPLETE}}", CompleteName,
+	).Replace(tpl))
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor methods
+// /////////////////////////////////////////////////////////////
+
+func (o *Command) InitField() *Command {
+	o.Command = managers.NewCommand(o.Name)
+	o.Command.SetDescription(CmdDesc).SetHandler(o.Handle)
+	return o
+}
+
+func (o *Command) InitOption() *Command {
+	if o.Err = o.Command.AddOption(
+		managers.NewOption(OptProgram).SetShortName(OptProgramByte).SetDescription(OptProgramDesc).SetDefault(filepath.Base(os.Args[0])),
+	); o.Err == nil {
+		o.Err = o.Command.AddPositional(
+			managers.NewPositional(ArgShell).SetDescription(ArgShellDesc).SetMode(managers.ModeRequired),
+		)
+	}
+	return o
+}
+
+// New
+// function create and return instance.
+//
+//   ./demo completion bash
+//   ./demo completion zsh --program=demo
+func New() (managers.Command, error) {
+	o := (&Command{Name: CmdName}).
+		InitField().
+		InitOption()
+
+	return o.Command, o.Err
+}
@@ -0,0 +1,251 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-18
+
+package completion
+
+import (
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"sort"
+	"strings"
+)
+
+const (
+	CompleteDesc = "Print completion candidates of words, called by completion scripts"
+	CompleteName = "__complete"
+
+	ArgWords     = "words"
+	ArgWordsDesc = "Words typed on command line, the last one is completing"
+)
+
+// Complete
+// hidden command called by shell completion scripts.
+type Complete struct {
+	Command managers.Command
+	Err     error
+	Name    string
+}
+
+// Handle
+// print candidates line by line.
+func (o *Complete) Handle(m managers.Manager, a managers.Arguments) (err error) {
+	for _, s := range o.Candidates(m, a.GetPositionalSlice(ArgWords)) {
+		if _, err = fmt.Fprintln(a.GetIO().Out, s); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Candidates
+// return sorted candidates of the last word.
+//
+//   consul kv d        -> download
+//   consul kv upload - -> --addr, --name, -a, -n ...
+func (o *Complete) Candidates(m managers.Manager, words []string) (list []string) {
+	var (
+		cmd      managers.Command
+		children = m.GetCommands()
+		current  string
+		help     bool
+	)
+
+	// Split completed
+	// words and current word.
+	if n := len(words); n > 0 {
+		current, words = strings.TrimSpace(words[n-1]), words[:n-1]
+	}
+
+	// Range completed words.
+	for i, s := range words {
+		// Stop completion
+		// after terminator.
+		if s == managers.ArgumentsTerminator {
+			return
+		}
+
+		// Option word.
+		if strings.HasPrefix(s, "-") {
+			continue
+		}
+
+		// Option value
+		// if previous option accept a value.
+		if i > 0 && o.accept(m, cmd, words[i-1]) {
+			continue
+		}
+
+		// Command path.
+		if c := o.child(m, cmd, s); c != nil {
+			// Help command
+			// complete paths from manager.
+			if c.GetName() == managers.ArgumentsHelp && cmd == nil {
+				help = true
+				continue
+			}
+
+			cmd, children = c, c.GetCommands()
+		}
+	}
+
+	// Return enum values
+	// if previous option require a value, global options are
+	// used if command not selected.
+	if n := len(words); n > 0 && o.accept(m, cmd, words[n-1]) {
+		return o.values(m, cmd, words[n-1], "", current)
+	}
+
+	// Enum values
+	// of option with equal sign, such as: --scheme=ht.
+	if i := strings.Index(current, "="); i > 0 && strings.HasPrefix(current, "-") {
+		return o.values(m, cmd, current[:i], current[:i+1], current[i+1:])
+	}
+
+	// Options
+	// of selected command.
+	if strings.HasPrefix(current, "-") {
+		if cmd != nil && !help {
+			list = o.options(m, cmd, current)
+		}
+		return
+	}
+
+	// Commands
+	// of selected group.
+	for name, c := range children {
+		if !c.GetHidden() && strings.HasPrefix(name, current) {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list)
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor methods
+// /////////////////////////////////////////////////////////////
+
+// Option word accept a value or not.
+func (o *Complete) accept(m managers.Manager, c managers.Command, word string) bool {
+	// Return false
+	// if word is not an option or value assigned.
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return false
+	}
+
+	if opt := o.option(m, c, word); opt != nil {
+		switch opt.GetValueType() {
+		case managers.ValueTypeBoolean, managers.ValueTypeCount, managers.ValueTypeNull:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Child command of group
+// or top level command of manager.
+func (o *Complete) child(m managers.Manager, parent managers.Command, key string) managers.Command {
+	if parent != nil {
+		return parent.GetCommand(key)
+	}
+	return m.GetCommand(key)
+}
+
+func (o *Complete) initField() *Complete {
+	o.Command = managers.NewCommand(o.Name)
+	o.Command.SetDescription(CompleteDesc).SetHidden(true).SetHandler(o.Handle)
+	return o
+}
+
+func (o *Complete) initOption() *Complete {
+	o.Err = o.Command.AddPositional(
+		managers.NewPositional(ArgWords).SetDescription(ArgWordsDesc).SetVariadic(true),
+	)
+	return o
+}
+
+// Option definition
+// of command or inherited options by option word, the last
+// character of short names or full name is used. Global options
+// are used if command is nil.
+func (o *Complete) option(m managers.Manager, c managers.Command, word string) managers.Option {
+	var key string
+
+	if strings.HasPrefix(word, "--") {
+		key = word[2:]
+	} else {
+		key = word[len(word)-1:]
+	}
+
+	if c != nil {
+		if opt := c.GetOption(key); opt != nil {
+			return opt
+		}
+	}
+	for _, opt := range m.GetInheritedOptions(c) {
+		if opt.GetName() == key || opt.GetShortName() == key {
+			return opt
+		}
+	}
+	return nil
+}
+
+// Option candidates
+// with full name and short name.
+func (o *Complete) options(m managers.Manager, c managers.Command, current string) (list []string) {
+	names := make([]string, 0)
+
+	// Command options.
+	for _, opt := range c.GetOptions() {
+		names = append(names, "--"+opt.GetName())
+		if opt.GetShortName() != "" {
+			names = append(names, "-"+opt.GetShortName())
+		}
+	}
+
+	// Inherited options,
+	// short name is used if not declared by command.
+	for _, opt := range m.GetInheritedOptions(c) {
+		names = append(names, "--"+opt.GetName())
+		if s := opt.GetShortName(); s != "" && c.GetOption(s) == nil {
+			names = append(names, "-"+s)
+		}
+	}
+
+	// Filter
+	// by current word.
+	for _, s := range names {
+		if strings.HasPrefix(s, current) {
+			list = append(list, s)
+		}
+	}
+	sort.Strings(list)
+	return
+}
+
+// Enum values
+// of option word filtered by current, prefix is prepended.
+func (o *Complete) values(m managers.Manager, c managers.Command, word, prefix, current string) (list []string) {
+	if opt := o.option(m, c, word); opt != nil {
+		for _, s := range opt.GetEnum() {
+			if strings.HasPrefix(s, current) {
+				list = append(list, prefix+s)
+			}
+		}
+	}
+	sort.Strings(list)
+	return
+}
+
+// NewComplete
+// function create and return hidden complete command.
+//
+//   ./demo __complete -- consul kv d
+func NewComplete() (managers.Command, error) {
+	o := (&Complete{Name: CompleteName}).
+		initField().
+		initOption()
+
+	return o.Command, o.Err
+}
@@ -0,0 +1,56 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package completion
+
+import (
+	"github.com/fuyibing/console/v3/managers"
+	"strings"
+	"testing"
+)
+
+func TestCompleteGlobalValues(t *testing.T) {
+	type params struct {
+		Override bool   `console:"override,o" desc:"Override" default:"false"`
+		Scheme   string `console:"scheme,s" desc:"Scheme" enum:"http,https"`
+	}
+
+	var (
+		m = managers.NewManager()
+		o = &Complete{}
+	)
+
+	download := managers.NewCommand("download")
+	if err := download.BindStruct(&params{}); err != nil {
+		t.Fatalf("bind struct failed: %v", err)
+	}
+	for _, c := range []managers.Command{download, managers.NewCommand("upload")} {
+		if err := m.AddCommand(c); err != nil {
+			t.Fatalf("add command failed: %v", err)
+		}
+	}
+
+	for _, x := range []struct {
+		Words  []string
+		Expect string
+	}{
+		// Top level
+		// global option.
+		{Words: []string{"--output", ""}, Expect: "csv,json,table,yaml"},
+		{Words: []string{"-o", ""}, Expect: "csv,json,table,yaml"},
+		{Words: []string{"-o", "j"}, Expect: "json"},
+		{Words: []string{"-o=y"}, Expect: "-o=yaml"},
+		{Words: []string{"-o", "json", "up"}, Expect: "upload"},
+
+		// Command option
+		// shadows short name of global option.
+		{Words: []string{"download", "-o", ""}, Expect: ""},
+		{Words: []string{"download", "-s", "http"}, Expect: "http,https"},
+		{Words: []string{"download", "--output", "t"}, Expect: "table"},
+		{Words: []string{"upload", "-o", ""}, Expect: "csv,json,table,yaml"},
+	} {
+		if list := o.Candidates(m, x.Words); strings.Join(list, ",") != x.Expect {
+			t.Errorf("%q: %v, expect: %s", x.Words, list, x.Expect)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-18
+
+package completion
+
+import (
+	"regexp"
+)
+
+const (
+	ScriptBash = `# bash completion for {{PROGRAM}}
+_{{FUNC}}_completion() {
+    local IFS=$'\n'
+    COMPREPLY=( $("{{PROGRAM}}" {{COMPLETE}} -- "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null) )
+}
+complete -o default -F _{{FUNC}}_completion {{PROGRAM}}
+`
+
+	ScriptFish = `# fish completion for {{PROGRAM}}
+function __{{FUNC}}_completion
+    set -l tokens (commandline -opc) (commandline -ct)
+    {{PROGRAM}} {{COMPLETE}} -- $tokens[2..-1] 2>/dev/null
+end
+complete -c {{PROGRAM}} -f -a '(__{{FUNC}}_completion)'
+`
+
+	ScriptPowershell = `# powershell completion for {{PROGRAM}}
+Register-ArgumentCompleter -Native -CommandName '{{PROGRAM}}' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') { $words += ' ' }
+    & '{{PROGRAM}}' {{COMPLETE}} -- @words 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+
+	ScriptZsh = `#compdef {{PROGRAM}}
+# zsh completion for {{PROGRAM}}
+_{{FUNC}}_completion() {
+    local -a candidates
+    candidates=("${(@f)$({{PROGRAM}} {{COMPLETE}} -- "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    compadd -a candidates
+}
+compdef _{{FUNC}}_completion {{PROGRAM}}
+`
+)
+
+var (
+	// Scripts
+	// completion script templates by shell name.
+	Scripts = map[string]string{
+		"bash":       ScriptBash,
+		"fish":       ScriptFish,
+		"powershell": ScriptPowershell,
+		"zsh":        ScriptZsh,
+	}
+
+	regexIdentifier = regexp.MustCompile(`[^_a-zA-Z0-9]+`)
+)
+
+// Identifier
+// convert program name as shell function name.
+func Identifier(program string) string {
+	return regexIdentifier.ReplaceAllString(program, "_")
+}
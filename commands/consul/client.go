@@ -4,7 +4,10 @@
 package consul
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"github.com/fuyibing/console/v3/managers"
 	"github.com/hashicorp/consul/api"
 	"os"
 	"strings"
@@ -25,7 +28,7 @@ type (
 
 // Deregister
 // remove service of consul.
-func (o *ClientManager) Deregister(cfg *api.Config, serviceName, serviceId string) (res map[string]interface{}, err error) {
+func (o *ClientManager) Deregister(ctx context.Context, cfg *api.Config, serviceName, serviceId string) (res map[string]interface{}, err error) {
 	var (
 		cli  *api.Client
 		idx  = 0
@@ -46,13 +49,20 @@ func (o *ClientManager) Deregister(cfg *api.Config, serviceName, serviceId strin
 
 	// List service
 	// by name.
-	if list, _, err = cli.Catalog().Service(serviceName, "", nil); err != nil {
+	if list, _, err = cli.Catalog().Service(serviceName, "", (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+		err = managers.NewRemoteError(err)
 		res[serviceName] = err
 		return
 	}
 
 	// Range service.
 	for _, item := range list {
+		// Stop
+		// if context cancelled.
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
 		if serviceId != "*" && serviceId != item.ServiceID {
 			continue
 		}
@@ -65,7 +75,7 @@ func (o *ClientManager) Deregister(cfg *api.Config, serviceName, serviceId strin
 		// Send deregister request.
 		if _, de := cli.Catalog().Deregister(&api.CatalogDeregistration{
 			Node: item.Node, ServiceID: item.ServiceID,
-		}, nil); de != nil {
+		}, (&api.WriteOptions{}).WithContext(ctx)); de != nil {
 			res[key] = de
 		} else {
 			res[key] = "deleted"
@@ -76,11 +86,16 @@ func (o *ClientManager) Deregister(cfg *api.Config, serviceName, serviceId strin
 }
 
 // Download
-// remote configuration from consul and store as local files.
-func (o *ClientManager) Download(cfg *api.Config, key, path string, override bool) (res map[string]interface{}, err error) {
+// remote configuration from consul and store as local files. The
+// ModifyIndex of key is recorded in lock file if all files saved.
+func (o *ClientManager) Download(ctx context.Context, cfg *api.Config, key, path string, override bool) (res map[string]interface{}, err error) {
 	var (
-		cli  *api.Client
-		text string
+		cli     *api.Client
+		ignored bool
+		index   uint64
+		lock    *Lock
+		saved   bool
+		text    string
 	)
 
 	// Prepare
@@ -93,23 +108,42 @@ func (o *ClientManager) Download(cfg *api.Config, key, path string, override boo
 		return
 	}
 
+	// Read lock
+	// of config directory.
+	if lock, err = LoadLock(path); err != nil {
+		return
+	}
+
 	// Read
 	// key contents from consul.
-	if text, err = o.keyReader(cli, res, key); err == nil {
-		for _, rows := range o.keySplit(text) {
-			if len(rows) > 1 {
-				if err = o.keySave(res, override, path, rows[0], rows[1:]); err != nil {
-					break
-				}
+	if text, index, err = o.keyReader(ctx, cli, res, key); err != nil {
+		return
+	}
+
+	// Save files.
+	for _, rows := range o.keySplit(text) {
+		if len(rows) > 1 {
+			if saved, err = o.keySave(res, override, path, rows[0], rows[1:]); err != nil {
+				return
+			}
+			if !saved {
+				ignored = true
 			}
 		}
 	}
+
+	// Record index
+	// if local copy refreshed.
+	if !ignored {
+		lock.Set(key, index)
+		err = lock.Save()
+	}
 	return
 }
 
 // Register
 // add new service to consul.
-func (o *ClientManager) Register(cfg *api.Config, req *api.AgentServiceRegistration) (res map[string]interface{}, err error) {
+func (o *ClientManager) Register(ctx context.Context, cfg *api.Config, req *api.AgentServiceRegistration) (res map[string]interface{}, err error) {
 	var (
 		cli *api.Client
 	)
@@ -130,17 +164,20 @@ func (o *ClientManager) Register(cfg *api.Config, req *api.AgentServiceRegistrat
 
 	// Build
 	// consul api client.
-	if cli, err = api.NewClient(cfg); err != nil {
-		err = cli.Agent().ServiceRegister(req)
+	if cli, err = api.NewClient(cfg); err == nil {
+		err = managers.NewRemoteError(cli.Agent().ServiceRegisterOpts(req, api.ServiceRegisterOpts{}.WithContext(ctx)))
 	}
 	return
 }
 
 // Upload
-// read local config files contents and put to consul.
-func (o *ClientManager) Upload(cfg *api.Config, key, path string) (res map[string]interface{}, err error) {
+// read local config files contents and put to consul. Check-and-set
+// is used with ModifyIndex recorded in lock file, return conflict
+// error if remote key changed since download unless force enabled.
+func (o *ClientManager) Upload(ctx context.Context, cfg *api.Config, key, path string, force bool) (res map[string]interface{}, err error) {
 	var (
 		cli  *api.Client
+		lock *Lock
 		text string
 	)
 
@@ -148,6 +185,12 @@ func (o *ClientManager) Upload(cfg *api.Config, key, path string) (res map[strin
 	// uploaded results.
 	res = make(map[string]interface{})
 
+	// Read lock
+	// of config directory.
+	if lock, err = LoadLock(path); err != nil {
+		return
+	}
+
 	// Read contents.
 	if text, err = o.ymlReader(res, path); err != nil {
 		return
@@ -159,12 +202,13 @@ func (o *ClientManager) Upload(cfg *api.Config, key, path string) (res map[strin
 		return
 	}
 
-	// Build params and send upload request.
-	_, err = cli.KV().Put(&api.KVPair{
-		Key:   key,
-		Value: []byte(text),
-	}, nil)
-
+	// Send upload request
+	// and record new index.
+	if err = o.keyPut(ctx, cli, lock, key, []byte(text), force); err != nil {
+		res[key] = err
+		return
+	}
+	err = lock.Save()
 	return
 }
 
@@ -179,8 +223,8 @@ func (o *ClientManager) init() *ClientManager {
 }
 
 // Read contents
-// from consul.
-func (o *ClientManager) keyReader(c *api.Client, res map[string]interface{}, key string) (text string, err error) {
+// from consul, index is ModifyIndex of key.
+func (o *ClientManager) keyReader(ctx context.Context, c *api.Client, res map[string]interface{}, key string) (text string, index uint64, err error) {
 	var (
 		k  = fmt.Sprintf("%v", key)
 		kp *api.KVPair
@@ -195,21 +239,23 @@ func (o *ClientManager) keyReader(c *api.Client, res map[string]interface{}, key
 	}()
 
 	// Get contents by key.
-	if kp, _, err = c.KV().Get(key, nil); err != nil {
+	if kp, _, err = c.KV().Get(key, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+		err = managers.NewRemoteError(err)
 		return
 	}
 
 	// Return
 	// if not found.
 	if kp == nil {
-		err = fmt.Errorf("not found")
+		err = managers.NewNotFoundError("key not found: %s", key)
 		return
 	}
 
 	// Replace variables like `kv://name`
+	index = kp.ModifyIndex
 	text = RegexDepth.ReplaceAllStringFunc(string(kp.Value), func(s string) string {
 		if m := RegexDepth.FindStringSubmatch(s); len(m) == 2 {
-			if sr, se := o.keyReader(c, res, m[1]); se == nil {
+			if sr, _, se := o.keyReader(ctx, c, res, m[1]); se == nil {
 				return sr
 			}
 		}
@@ -261,9 +307,47 @@ func (o *ClientManager) keySplit(text string) (res [][]string) {
 	return
 }
 
+// Put value
+// with check-and-set against index recorded in lock, zero index
+// means key must not exist. Index in lock refreshed if succeed
+// and value not changed by other writer.
+func (o *ClientManager) keyPut(ctx context.Context, c *api.Client, lock *Lock, key string, value []byte, force bool) (err error) {
+	var (
+		kp *api.KVPair
+		ok bool
+		wo = (&api.WriteOptions{}).WithContext(ctx)
+	)
+
+	// Send put request.
+	if force {
+		_, err = c.KV().Put(&api.KVPair{Key: key, Value: value}, wo)
+	} else if ok, _, err = c.KV().CAS(&api.KVPair{Key: key, Value: value, ModifyIndex: lock.Get(key)}, wo); err == nil && !ok {
+		ce := &ConflictError{Key: key, Local: lock.Get(key)}
+		if kp, _, err = c.KV().Get(key, (&api.QueryOptions{}).WithContext(ctx)); err == nil {
+			if kp != nil {
+				ce.Remote = kp.ModifyIndex
+			}
+			err = ce
+		}
+	}
+	if err != nil {
+		err = managers.WrapError(managers.ErrorKindRemote, err)
+		return
+	}
+
+	// Refresh index
+	// if value read back is ours, index of other writer changed key
+	// after our put is not recorded, so next upload is conflicted.
+	if kp, _, err = c.KV().Get(key, (&api.QueryOptions{}).WithContext(ctx)); err == nil && kp != nil && bytes.Equal(kp.Value, value) {
+		lock.Set(key, kp.ModifyIndex)
+	}
+	return
+}
+
 // Save contents
-// to local yaml files.
-func (o *ClientManager) keySave(res map[string]interface{}, override bool, path, name string, line []string) (err error) {
+// to local yaml files, saved is false if file exists and not
+// override.
+func (o *ClientManager) keySave(res map[string]interface{}, override bool, path, name string, line []string) (saved bool, err error) {
 	var (
 		fp       *os.File
 		fullPath = fmt.Sprintf("%s/%s", path, name)
@@ -272,7 +356,7 @@ func (o *ClientManager) keySave(res map[string]interface{}, override bool, path,
 
 	// Not override.
 	if !override {
-		if s, se := os.Stat(path); se == nil && !s.IsDir() {
+		if s, se := os.Stat(fullPath); se == nil && !s.IsDir() {
 			res[k] = "ignored"
 			return
 		}
@@ -296,7 +380,9 @@ func (o *ClientManager) keySave(res map[string]interface{}, override bool, path,
 	}
 
 	// Write string.
-	_, err = fp.WriteString(strings.Join(line, "\n"))
+	if _, err = fp.WriteString(strings.Join(line, "\n")); err == nil {
+		saved = true
+	}
 	return
 }
 
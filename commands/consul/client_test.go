@@ -0,0 +1,193 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"context"
+	"errors"
+	"github.com/fuyibing/console/v3/commands/consul/consultest"
+	"github.com/fuyibing/console/v3/managers"
+	"github.com/hashicorp/consul/api"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClientDownload(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	index := srv.Put("app/myapp", "db.yaml:\n  host: 127.0.0.1\nredis.yaml:\n  port: 6379\n")
+
+	if _, err := Client.Download(ctx, srv.Config(), "app/myapp", path, false); err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if s := readFile(t, filepath.Join(path, "db.yaml")); s != "host: 127.0.0.1" {
+		t.Errorf("db.yaml contents: %q", s)
+	}
+	if s := readFile(t, filepath.Join(path, "redis.yaml")); s != "port: 6379" {
+		t.Errorf("redis.yaml contents: %q", s)
+	}
+	if lock, _ := LoadLock(path); lock.Get("app/myapp") != index {
+		t.Errorf("lock index: %d, expect: %d", lock.Get("app/myapp"), index)
+	}
+}
+
+func TestClientDownloadNotFound(t *testing.T) {
+	srv := consultest.NewServer()
+	defer srv.Close()
+
+	_, err := Client.Download(context.Background(), srv.Config(), "app/none", t.TempDir(), false)
+	if code := managers.ErrorCode(err); code != managers.ExitCodeNotFound {
+		t.Fatalf("exit code: %d, expect: %d, error: %v", code, managers.ExitCodeNotFound, err)
+	}
+}
+
+func TestClientUpload(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	writeFile(t, filepath.Join(path, "db.yaml"), "host: 127.0.0.1\n")
+
+	if _, err := Client.Upload(ctx, srv.Config(), "app/myapp", path, false); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	s, ok := srv.Get("app/myapp")
+	if !ok || !strings.HasPrefix(s, "db.yaml:") || !strings.Contains(s, "\n  host: 127.0.0.1\n") {
+		t.Fatalf("remote value: %q", s)
+	}
+	if lock, _ := LoadLock(path); lock.Get("app/myapp") != srv.Index("app/myapp") {
+		t.Errorf("lock index: %d, expect: %d", lock.Get("app/myapp"), srv.Index("app/myapp"))
+	}
+}
+
+func TestClientUploadConflict(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	srv.Put("app/myapp", "db.yaml:\n  host: 127.0.0.1\n")
+	if _, err := Client.Download(ctx, srv.Config(), "app/myapp", path, false); err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+
+	// Changed
+	// by other writer since download.
+	remote := srv.Put("app/myapp", "db.yaml:\n  host: 10.0.0.1\n")
+	writeFile(t, filepath.Join(path, "db.yaml"), "host: 192.168.0.1\n")
+
+	_, err := Client.Upload(ctx, srv.Config(), "app/myapp", path, false)
+
+	var ce *ConflictError
+	if !errors.As(err, &ce) {
+		t.Fatalf("conflict error expected, got: %v", err)
+	}
+	if ce.Remote != remote {
+		t.Errorf("conflict remote index: %d, expect: %d", ce.Remote, remote)
+	}
+	if s, _ := srv.Get("app/myapp"); !strings.Contains(s, "10.0.0.1") {
+		t.Errorf("remote value overwritten: %q", s)
+	}
+
+	// Force
+	// override remote changes.
+	if _, err = Client.Upload(ctx, srv.Config(), "app/myapp", path, true); err != nil {
+		t.Fatalf("force upload failed: %v", err)
+	}
+	if s, _ := srv.Get("app/myapp"); !strings.Contains(s, "192.168.0.1") {
+		t.Errorf("remote value not overwritten: %q", s)
+	}
+	if lock, _ := LoadLock(path); lock.Get("app/myapp") != srv.Index("app/myapp") {
+		t.Errorf("lock index: %d, expect: %d", lock.Get("app/myapp"), srv.Index("app/myapp"))
+	}
+}
+
+func TestClientRegister(t *testing.T) {
+	var (
+		file = filepath.Join(t.TempDir(), "service.yaml")
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	writeFile(t, file, "service:\n  id: myapp-1\n  name: myapp\n  port: 8080\n  tags: [v1]\n  check:\n    http: http://127.0.0.1:8080/health\n    interval: 10s\n")
+
+	req, err := LoadRegistration(file)
+	if err != nil {
+		t.Fatalf("load registration failed: %v", err)
+	}
+	if _, err = Client.Register(context.Background(), srv.Config(), req); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	s, ok := srv.Service("myapp-1")
+	if !ok {
+		t.Fatalf("service not registered")
+	}
+	if s.Name != "myapp" || s.Port != 8080 || len(s.Tags) != 1 || s.Tags[0] != "v1" {
+		t.Errorf("service registered: %+v", s)
+	}
+	if s.Check == nil || s.Check.HTTP != "http://127.0.0.1:8080/health" || s.Check.Interval != "10s" {
+		t.Errorf("service check: %+v", s.Check)
+	}
+}
+
+func TestClientDeregister(t *testing.T) {
+	srv := consultest.NewServer()
+	defer srv.Close()
+
+	srv.Register(&api.AgentServiceRegistration{ID: "myapp-1", Name: "myapp"})
+	srv.Register(&api.AgentServiceRegistration{ID: "myapp-2", Name: "myapp"})
+	srv.Register(&api.AgentServiceRegistration{ID: "other-1", Name: "other"})
+
+	// One instance.
+	if _, err := Client.Deregister(context.Background(), srv.Config(), "myapp", "myapp-1"); err != nil {
+		t.Fatalf("deregister failed: %v", err)
+	}
+	if _, ok := srv.Service("myapp-1"); ok {
+		t.Errorf("service not deregistered: myapp-1")
+	}
+	if _, ok := srv.Service("myapp-2"); !ok {
+		t.Errorf("service deregistered: myapp-2")
+	}
+
+	// All instances.
+	if _, err := Client.Deregister(context.Background(), srv.Config(), "myapp", "*"); err != nil {
+		t.Fatalf("deregister failed: %v", err)
+	}
+	if list := srv.Services(); len(list) != 1 || list[0].ID != "other-1" {
+		t.Errorf("services remained: %d", len(list))
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file failed: %v", err)
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+func writeFile(t *testing.T, path, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatalf("create directory failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(text), os.ModePerm); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+}
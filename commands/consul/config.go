@@ -0,0 +1,117 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-19
+
+package consul
+
+import (
+	"github.com/fuyibing/console/v3/managers"
+	"github.com/hashicorp/consul/api"
+)
+
+// Config
+// shared options of consul commands.
+//
+//   -a, --addr=<string>               Consul server address
+//   -s, --scheme[=string]             Consul server scheme
+//       --token[=string]              ACL token
+//       --token-file[=file]           File contains ACL token
+//       --ca-file[=file]              CA certificate file
+//       --cert-file[=file]            Client certificate file
+//       --key-file[=file]             Client private key file
+//       --insecure-skip-verify        Skip TLS verification
+//       --datacenter[=string]         Datacenter
+//       --namespace[=string]          Namespace, enterprise only
+//       --partition[=string]          Admin partition, enterprise only
+type Config struct {
+	Addr   string `console:"addr,a,required" desc:"Consul server address, such as: 127.0.0.1, consul.example.com" env:"CONSUL_HTTP_ADDR" config:"consul.addr"`
+	Scheme string `console:"scheme,s" desc:"Consul server scheme" default:"http" enum:"http,https" config:"consul.scheme"`
+
+	Token     string `console:"token" desc:"Consul ACL token" env:"CONSUL_HTTP_TOKEN" config:"consul.token"`
+	TokenFile string `console:"token-file" desc:"File contains consul ACL token" env:"CONSUL_HTTP_TOKEN_FILE" config:"consul.token_file" type:"file"`
+
+	CaFile             string `console:"ca-file" desc:"CA certificate file to verify consul server" env:"CONSUL_CACERT" config:"consul.ca_file" type:"file"`
+	CertFile           string `console:"cert-file" desc:"Client certificate file for mTLS" env:"CONSUL_CLIENT_CERT" config:"consul.cert_file" type:"file"`
+	KeyFile            string `console:"key-file" desc:"Client private key file for mTLS" env:"CONSUL_CLIENT_KEY" config:"consul.key_file" type:"file"`
+	InsecureSkipVerify bool   `console:"insecure-skip-verify" desc:"Skip TLS certificate verification" default:"false" config:"consul.insecure_skip_verify"`
+
+	Datacenter string `console:"datacenter" desc:"Consul datacenter, default is datacenter of agent" config:"consul.datacenter"`
+	Namespace  string `console:"namespace" desc:"Consul namespace, enterprise only" env:"CONSUL_NAMESPACE" config:"consul.namespace"`
+	Partition  string `console:"partition" desc:"Consul admin partition, enterprise only" env:"CONSUL_PARTITION" config:"consul.partition"`
+}
+
+// ConfigGroups
+// return constraint groups of config options, added on command
+// group which binds config as persistent options.
+//
+//   err = c.AddOptionGroup(consul.ConfigGroups()...)
+func ConfigGroups() []managers.OptionGroup {
+	return []managers.OptionGroup{
+		managers.NewExclusiveGroup("token", "token-file"),
+		managers.NewRequiresGroup("cert-file", "key-file"),
+	}
+}
+
+// CheckConfig
+// return error if config options not bound to invocation, config
+// options are inherited from command group, so command mounted
+// without the group can not read server address of user.
+//
+//   if err = consul.CheckConfig(a); err != nil {
+//       return
+//   }
+func CheckConfig(a managers.Arguments) error {
+	b, err := managers.NewBinder(&Config{})
+	if err != nil {
+		return err
+	}
+
+	for _, opt := range b.GetOptions() {
+		if a.GetOption(opt.GetName()) == nil {
+			return managers.NewError(managers.ErrorKindFailure, "consul config option not bound: --%s", opt.GetName()).
+				SetHint("Bind consul.Config as persistent options of parent command, such as consul command group")
+		}
+	}
+	return nil
+}
+
+// ApiConfig
+// build consul api config with option values.
+func (o *Config) ApiConfig() *api.Config {
+	cfg := api.DefaultNonPooledConfig()
+	cfg.Address = o.Addr
+	cfg.Scheme = o.Scheme
+
+	// ACL token.
+	if o.Token != "" {
+		cfg.Token = o.Token
+	}
+	if o.TokenFile != "" {
+		cfg.TokenFile = o.TokenFile
+	}
+
+	// TLS files.
+	if o.CaFile != "" {
+		cfg.TLSConfig.CAFile = o.CaFile
+	}
+	if o.CertFile != "" {
+		cfg.TLSConfig.CertFile = o.CertFile
+	}
+	if o.KeyFile != "" {
+		cfg.TLSConfig.KeyFile = o.KeyFile
+	}
+	if o.InsecureSkipVerify {
+		cfg.TLSConfig.InsecureSkipVerify = true
+	}
+
+	// Scopes.
+	if o.Datacenter != "" {
+		cfg.Datacenter = o.Datacenter
+	}
+	if o.Namespace != "" {
+		cfg.Namespace = o.Namespace
+	}
+	if o.Partition != "" {
+		cfg.Partition = o.Partition
+	}
+	return cfg
+}
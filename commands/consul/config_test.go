@@ -0,0 +1,46 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"bytes"
+	"context"
+	"github.com/fuyibing/console/v3/managers"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	for _, bound := range []bool{true, false} {
+		var (
+			args = []string{"demo", "consul", "check"}
+			m    = managers.NewManager()
+			g    = managers.NewCommand("consul")
+			c    = managers.NewCommand("check")
+		)
+
+		c.SetHandler(func(_ managers.Manager, a managers.Arguments) error {
+			return CheckConfig(a)
+		})
+		if bound {
+			if err := g.BindPersistentStruct(&Config{}); err != nil {
+				t.Fatalf("bind persistent struct failed: %v", err)
+			}
+			args = append(args, "--addr=127.0.0.1")
+		}
+		if err := g.AddCommand(c); err != nil {
+			t.Fatalf("add command failed: %v", err)
+		}
+		if err := m.AddCommand(g); err != nil {
+			t.Fatalf("add command failed: %v", err)
+		}
+
+		res := m.Execute(context.Background(), &managers.IO{Out: &bytes.Buffer{}, Err: &bytes.Buffer{}}, args...)
+		if bound && res.Err != nil {
+			t.Errorf("bound config: %v", res.Err)
+		}
+		if !bound && (res.Err == nil || res.Code != managers.ExitCodeFailure) {
+			t.Errorf("unbound config, code: %d, error: %v", res.Code, res.Err)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+// Package consultest
+// in-process fake consul server for testing consul commands without
+// a live agent. Endpoints used by consul.ClientManager are served
+// and state can be seeded and inspected.
+//
+//   srv := consultest.NewServer()
+//   defer srv.Close()
+//
+//   srv.Put("app/myapp", "db.yaml:\n  host: 127.0.0.1\n")
+//   res, err := consul.Client.Download(ctx, srv.Config(), "app/myapp", "./config", true)
+package consultest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/consul/api"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultNode
+// name of node which services registered on.
+const DefaultNode = "consultest"
+
+type (
+	// Server
+	// fake consul server.
+	Server struct {
+		Server *httptest.Server
+
+		index    uint64
+		kv       map[string]*api.KVPair
+		mu       sync.RWMutex
+		requests []*Request
+		services map[string]*api.AgentServiceRegistration
+	}
+
+	// Request
+	// received by server.
+	Request struct {
+		Method string
+		Path   string
+		Query  string
+		Token  string
+	}
+)
+
+// NewServer
+// create and start fake consul server.
+func NewServer() *Server {
+	o := &Server{
+		kv:       make(map[string]*api.KVPair),
+		requests: make([]*Request, 0),
+		services: make(map[string]*api.AgentServiceRegistration),
+	}
+	o.Server = httptest.NewServer(http.HandlerFunc(o.serve))
+	return o
+}
+
+// /////////////////////////////////////////////////////////////
+// Server and config
+// /////////////////////////////////////////////////////////////
+
+// Addr
+// return server address, such as: 127.0.0.1:8500.
+func (o *Server) Addr() string { return strings.TrimPrefix(o.Server.URL, "http://") }
+
+// Close
+// stop server.
+func (o *Server) Close() { o.Server.Close() }
+
+// Config
+// return consul api config connected to server.
+func (o *Server) Config() *api.Config {
+	cfg := api.DefaultNonPooledConfig()
+	cfg.Address = o.Addr()
+	cfg.Scheme = "http"
+	return cfg
+}
+
+// /////////////////////////////////////////////////////////////
+// State methods
+// /////////////////////////////////////////////////////////////
+
+// Delete
+// remove key.
+func (o *Server) Delete(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.kv, key)
+}
+
+// Get
+// return value of key.
+func (o *Server) Get(key string) (string, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if kp, ok := o.kv[key]; ok {
+		return string(kp.Value), true
+	}
+	return "", false
+}
+
+// Index
+// return ModifyIndex of key, zero returned if not exists.
+func (o *Server) Index(key string) uint64 {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if kp, ok := o.kv[key]; ok {
+		return kp.ModifyIndex
+	}
+	return 0
+}
+
+// Keys
+// return sorted key names.
+func (o *Server) Keys() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	list := make([]string, 0)
+	for k := range o.kv {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Put
+// set value of key and return new ModifyIndex.
+func (o *Server) Put(key, value string) uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.put(key, []byte(value))
+}
+
+// Register
+// add service to agent.
+func (o *Server) Register(req *api.AgentServiceRegistration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.register(req)
+}
+
+// Requests
+// return received requests in order.
+func (o *Server) Requests() []*Request {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return append([]*Request{}, o.requests...)
+}
+
+// Service
+// return registered service by id.
+func (o *Server) Service(id string) (*api.AgentServiceRegistration, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	req, ok := o.services[id]
+	return req, ok
+}
+
+// Services
+// return registered services sorted by id.
+func (o *Server) Services() []*api.AgentServiceRegistration {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	list := make([]*api.AgentServiceRegistration, 0)
+	for _, req := range o.services {
+		list = append(list, req)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and construct methods
+// /////////////////////////////////////////////////////////////
+
+// Catalog
+// deregister, remove service of default node.
+func (o *Server) catalogDeregister(w http.ResponseWriter, r *http.Request) {
+	req := &api.CatalogDeregistration{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		o.error(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Node == DefaultNode {
+		delete(o.services, req.ServiceID)
+	}
+	o.write(w, true)
+}
+
+// Catalog
+// services by name.
+func (o *Server) catalogService(w http.ResponseWriter, name string) {
+	list := make([]*api.CatalogService, 0)
+	for _, req := range o.services {
+		if req.Name == name {
+			list = append(list, &api.CatalogService{
+				Node:           DefaultNode,
+				ServiceAddress: req.Address,
+				ServiceID:      req.ID,
+				ServiceMeta:    req.Meta,
+				ServiceName:    req.Name,
+				ServicePort:    req.Port,
+				ServiceTags:    req.Tags,
+			})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ServiceID < list[j].ServiceID
+	})
+	o.write(w, list)
+}
+
+func (o *Server) error(w http.ResponseWriter, code int, err error) {
+	w.WriteHeader(code)
+	_, _ = io.WriteString(w, err.Error())
+}
+
+// Delete
+// key or keys with prefix.
+func (o *Server) kvDelete(w http.ResponseWriter, r *http.Request, key string) {
+	q := r.URL.Query()
+
+	// Delete keys
+	// with prefix.
+	if _, ok := q["recurse"]; ok {
+		for k := range o.kv {
+			if strings.HasPrefix(k, key) {
+				delete(o.kv, k)
+			}
+		}
+		o.write(w, true)
+		return
+	}
+
+	// Check and set.
+	if s := q.Get("cas"); s != "" {
+		kp, ok := o.kv[key]
+		if n, err := strconv.ParseUint(s, 10, 64); err != nil || !ok || n != kp.ModifyIndex {
+			o.write(w, false)
+			return
+		}
+	}
+
+	delete(o.kv, key)
+	o.write(w, true)
+}
+
+// Get
+// key, list keys with prefix or key names.
+func (o *Server) kvGet(w http.ResponseWriter, r *http.Request, key string) {
+	var (
+		list = make([]*api.KVPair, 0)
+		q    = r.URL.Query()
+	)
+
+	_, keys := q["keys"]
+	_, recurse := q["recurse"]
+
+	// Collect pairs.
+	for k, kp := range o.kv {
+		if k == key || ((keys || recurse) && strings.HasPrefix(k, key)) {
+			list = append(list, kp)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(o.index, 10))
+
+	// Return not found
+	// if no pair matched.
+	if len(list) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// Key names.
+	if keys {
+		names := make([]string, 0)
+		for _, kp := range list {
+			names = append(names, kp.Key)
+		}
+		o.write(w, names)
+		return
+	}
+
+	o.write(w, list)
+}
+
+// Put
+// key with check-and-set support.
+func (o *Server) kvPut(w http.ResponseWriter, r *http.Request, key string) {
+	var (
+		buf []byte
+		err error
+		q   = r.URL.Query()
+	)
+
+	if buf, err = io.ReadAll(r.Body); err != nil {
+		o.error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Check and set,
+	// zero index means key must not exist.
+	if s := q.Get("cas"); s != "" {
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, 64); err != nil {
+			o.error(w, http.StatusBadRequest, err)
+			return
+		}
+		kp, ok := o.kv[key]
+		if (n == 0 && ok) || (n != 0 && (!ok || kp.ModifyIndex != n)) {
+			o.write(w, false)
+			return
+		}
+	}
+
+	o.put(key, buf)
+	o.write(w, true)
+}
+
+func (o *Server) put(key string, value []byte) uint64 {
+	o.index++
+
+	kp, ok := o.kv[key]
+	if !ok {
+		kp = &api.KVPair{Key: key, CreateIndex: o.index}
+		o.kv[key] = kp
+	}
+	kp.Value = value
+	kp.ModifyIndex = o.index
+	return o.index
+}
+
+func (o *Server) register(req *api.AgentServiceRegistration) {
+	if req.ID == "" {
+		req.ID = req.Name
+	}
+	o.services[req.ID] = req
+}
+
+// Serve
+// http request.
+func (o *Server) serve(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Record request.
+	token := r.Header.Get("X-Consul-Token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	o.requests = append(o.requests, &Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Token: token})
+
+	// Route
+	// by path and method.
+	switch p := r.URL.Path; {
+	case strings.HasPrefix(p, "/v1/kv/"):
+		key := strings.TrimPrefix(p, "/v1/kv/")
+		switch r.Method {
+		case http.MethodGet:
+			o.kvGet(w, r, key)
+		case http.MethodPut:
+			o.kvPut(w, r, key)
+		case http.MethodDelete:
+			o.kvDelete(w, r, key)
+		default:
+			o.error(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		}
+	case p == "/v1/agent/service/register" && r.Method == http.MethodPut:
+		req := &api.AgentServiceRegistration{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			o.error(w, http.StatusBadRequest, err)
+			return
+		}
+		o.register(req)
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(p, "/v1/catalog/service/") && r.Method == http.MethodGet:
+		o.catalogService(w, strings.TrimPrefix(p, "/v1/catalog/service/"))
+	case p == "/v1/catalog/deregister" && r.Method == http.MethodPut:
+		o.catalogDeregister(w, r)
+	default:
+		o.error(w, http.StatusNotFound, fmt.Errorf("endpoint not supported: %s %s", r.Method, p))
+	}
+}
+
+func (o *Server) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,181 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DiffContext
+	// lines around changes in unified diff.
+	DiffContext = 3
+)
+
+type diffLine struct {
+	Op   byte
+	Text string
+}
+
+// Diff
+// return unified diff of two texts, empty string returned if
+// no difference.
+//
+//   --- remote/db.yaml
+//   +++ local/db.yaml
+//   @@ -1,2 +1,2 @@
+//    host: 127.0.0.1
+//   -port: 3306
+//   +port: 3307
+func Diff(from, to, fromName, toName string) string {
+	var (
+		a     = diffSplit(from)
+		b     = diffSplit(to)
+		lines = diffLines(a, b)
+		sb    strings.Builder
+	)
+
+	// Range hunks.
+	for i := 0; i < len(lines); {
+		// Find
+		// next change.
+		if lines[i].Op == ' ' {
+			i++
+			continue
+		}
+
+		// Hunk range
+		// with context lines.
+		start := i - DiffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines) && j <= end+DiffContext*2; j++ {
+			if lines[j].Op != ' ' {
+				end = j
+			}
+		}
+		if end += DiffContext + 1; end > len(lines) {
+			end = len(lines)
+		}
+
+		// Write header.
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+		}
+		sb.WriteString(diffHunk(lines, start, end))
+		i = end
+	}
+
+	return sb.String()
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and construct methods
+// /////////////////////////////////////////////////////////////
+
+// Build hunk
+// of lines in range.
+func diffHunk(lines []diffLine, start, end int) string {
+	var (
+		la, lb = 1, 1
+		na, nb = 0, 0
+		sb     strings.Builder
+	)
+
+	// Line number
+	// of hunk start.
+	for _, l := range lines[:start] {
+		if l.Op != '+' {
+			la++
+		}
+		if l.Op != '-' {
+			lb++
+		}
+	}
+
+	// Count lines.
+	for _, l := range lines[start:end] {
+		if l.Op != '+' {
+			na++
+		}
+		if l.Op != '-' {
+			nb++
+		}
+		sb.WriteString(fmt.Sprintf("%c%s\n", l.Op, l.Text))
+	}
+
+	// Empty side
+	// start with previous line.
+	if na == 0 {
+		la--
+	}
+	if nb == 0 {
+		lb--
+	}
+
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", la, na, lb, nb, sb.String())
+}
+
+// Compare lines
+// with longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	var (
+		lcs   = make([][]int, len(a)+1)
+		lines = make([]diffLine, 0)
+	)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// Walk table.
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+// Split text
+// as lines.
+func diffSplit(text string) []string {
+	list := make([]string, 0)
+	if text = strings.TrimSuffix(text, "\n"); text != "" {
+		for _, s := range strings.Split(text, "\n") {
+			list = append(list, strings.TrimRight(s, "\r"))
+		}
+	}
+	return list
+}
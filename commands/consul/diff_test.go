@@ -0,0 +1,59 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	for _, x := range []struct {
+		Name     string
+		From, To string
+		Expect   string
+	}{
+		{
+			Name:   "identical",
+			From:   "host: 127.0.0.1\nport: 3306\n",
+			To:     "host: 127.0.0.1\nport: 3306",
+			Expect: "",
+		},
+		{
+			Name:   "both empty",
+			Expect: "",
+		},
+		{
+			Name:   "empty old",
+			To:     "x\ny\n",
+			Expect: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			Name:   "empty new",
+			From:   "x\ny\n",
+			Expect: "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			Name:   "single hunk",
+			From:   "a\nb\nc\nd\ne\nf\ng\nh\n",
+			To:     "a\nB\nc\nd\ne\nf\nG\nh\n",
+			Expect: "--- a\n+++ b\n@@ -1,8 +1,8 @@\n a\n-b\n+B\n c\n d\n e\n f\n-g\n+G\n h\n",
+		},
+		{
+			Name:   "multi hunk",
+			From:   "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n",
+			To:     "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nK\nl\nm\n",
+			Expect: "--- a\n+++ b\n@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n@@ -8,5 +8,6 @@\n h\n i\n j\n-k\n+K\n l\n+m\n",
+		},
+		{
+			Name:   "crlf",
+			From:   "a\r\nb\r\n",
+			To:     "a\nb\n",
+			Expect: "",
+		},
+	} {
+		if s := Diff(x.From, x.To, "a", "b"); s != x.Expect {
+			t.Errorf("%s: diff:\n%s\nexpect:\n%s", x.Name, s, x.Expect)
+		}
+	}
+}
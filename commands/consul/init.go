@@ -10,6 +10,11 @@ import (
 	"sync"
 )
 
+// Option names
+// of consul commands.
+//
+// Deprecated: options are declared by struct tags of Config and
+// command params, constants are kept for compatibility only.
 const (
 	OptAddr     = "addr"
 	OptAddrByte = 'a'
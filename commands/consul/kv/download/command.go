@@ -7,102 +7,127 @@
 package download
 
 import (
+	"context"
 	"github.com/fuyibing/console/v3/commands/consul"
 	"github.com/fuyibing/console/v3/managers"
-	"github.com/hashicorp/consul/api"
 )
 
 const (
 	CmdDesc = "Download config from consul and save to local"
-	CmdName = "kv:download"
+	CmdName = "download"
 )
 
-// Command
-// for consul kv download.
-type Command struct {
-	Command managers.Command
-	Err     error
-	Name    string
-}
+type (
+	// Command
+	// for consul kv download.
+	Command struct {
+		Command managers.Command
+		Err     error
+		Name    string
+		Params  *Params
+	}
+
+	// Options
+	// declared by command.
+	Options struct {
+		Diff     bool   `console:"diff" desc:"Print unified diff of changes without writing" default:"false"`
+		DryRun   bool   `console:"dry-run" desc:"Print what would change without writing" default:"false"`
+		Key      string `console:"name,n,required" desc:"Consul key name"`
+		Override bool   `console:"override,o" desc:"Override config files if exists" default:"false"`
+		Path     string `console:"path,p" desc:"Config file storage location" default:"./config"`
+		Tree     bool   `console:"tree,t" desc:"Download each key under name as a file, key segments become directories" default:"false"`
+	}
+
+	// Params
+	// bound with command options, consul config options are
+	// inherited from consul command group.
+	Params struct {
+		consul.Config
+		Options
+	}
+)
 
 // Handle
 // send download request.
-func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
+func (o *Command) Handle(ctx context.Context, _ managers.Manager, a managers.Arguments) (err error) {
 	var (
-		cfg       = api.DefaultNonPooledConfig()
-		key, path = "", ""
-		keys      map[string]interface{}
-		override  bool
+		keys map[string]interface{}
+		p    = &Params{}
 	)
 
-	// Read address option.
-	//
-	//   -a consul.example.com
-	//   --addr consul.example.com
-	//   --addr="consul.example.com"
-	if cfg.Address, err = o.Command.GetOption(consul.OptAddr).ToString(); err != nil {
+	// Read options
+	// of invocation, config options must be inherited.
+	if err = consul.CheckConfig(a); err != nil {
 		return
 	}
-
-	// Read scheme option.
-	//
-	//   -s https
-	//   --scheme https
-	//   --scheme="https"
-	if cfg.Scheme, err = o.Command.GetOption(consul.OptScheme).ToString(); err != nil {
+	if err = a.Populate(p); err != nil {
 		return
 	}
 
-	// Read key name option.
-	//
-	//   -n app/myapp
-	//   --name app/myapp
-	//   --name="app/myapp"
-	if key, err = o.Command.GetOption(consul.OptKey).ToString(); err != nil {
-		return
+	// Compare only
+	// if dry run or diff enabled.
+	if p.DryRun || p.Diff {
+		return o.Plan(ctx, a, p)
 	}
 
-	// Config storage path.
-	if path, err = o.Command.GetOption(consul.OptPath).ToString(); err != nil {
-		return
+	// Send download request.
+	if p.Tree {
+		keys, err = consul.Client.DownloadTree(ctx, p.ApiConfig(), p.Key, p.Path, p.Override)
+	} else {
+		keys, err = consul.Client.Download(ctx, p.ApiConfig(), p.Key, p.Path, p.Override)
 	}
+	a.GetOutput().Map(keys, "Consul key downloaded results")
+	return
+}
+
+// Plan
+// compare without writing and print changes.
+func (o *Command) Plan(ctx context.Context, a managers.Arguments, p *Params) (err error) {
+	var (
+		list []*consul.Change
+	)
 
-	// Config override or not.
-	if override, err = o.Command.GetOption(consul.OptOverride).ToBool(); err != nil {
+	if list, err = consul.Client.PlanDownload(ctx, p.ApiConfig(), p.Key, p.Path, p.Override, p.Tree); err != nil {
 		return
 	}
-
-	// Send download request.
-	keys, err = consul.Client.Download(cfg, key, path, override)
-	managers.Output.Map(keys, "Consul key downloaded results")
-	return
+	return consul.RenderPlan(a.GetOutput(), list, p.Diff, "Consul key download plan")
 }
 
 // InitField
 // initialize command fields.
 func (o *Command) InitField() *Command {
 	o.Command = managers.NewCommand(o.Name)
-	o.Command.SetDescription(CmdDesc).SetHandler(o.Handle)
+	o.Command.SetDescription(CmdDesc).SetContextHandler(o.Handle)
 	return o
 }
 
 // InitOption
 // initialize command option.
 func (o *Command) InitOption() *Command {
-	o.Err = o.Command.AddOption(
-		managers.NewOption(consul.OptAddr).SetShortName(consul.OptAddrByte).SetDescription(consul.OptAddrDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptScheme).SetShortName(consul.OptSchemeByte).SetDescription(consul.OptSchemeDesc).SetDefault(consul.OptSchemeDefault),
-		managers.NewOption(consul.OptKey).SetShortName(consul.OptKeyByte).SetDescription(consul.OptKeyDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptOverride).SetShortName(consul.OptOverrideByte).SetDescription(consul.OptOverrideDesc).SetDefault(consul.OptOverrideDefault).SetValueType(managers.ValueTypeBoolean),
-		managers.NewOption(consul.OptPath).SetShortName(consul.OptPathByte).SetDescription(consul.OptPathDesc).SetDefault(consul.OptPathDefault),
-	)
+	o.Err = o.Command.BindStruct(&o.Params.Options)
 	return o
 }
 
 // New
 // create and return instance.
+//
+//   go run main.go consul kv download \
+//     --addr=consul.example.com \
+//     --name=app/myapp \
+//     --path=./config
+//
+//   go run main.go consul kv download \
+//     --addr=consul.example.com \
+//     --name=app/myapp \
+//     --path=./config \
+//     --tree
+//
+//   go run main.go consul kv download \
+//     --addr=consul.example.com \
+//     --name=app/myapp \
+//     --diff
 func New() (managers.Command, error) {
-	o := (&Command{Name: CmdName}).
+	o := (&Command{Name: CmdName, Params: &Params{}}).
 		InitField().
 		InitOption()
 
@@ -4,3 +4,26 @@
 // Package kv
 // read and write configuration by consul kv pair.
 package kv
+
+import (
+	"github.com/fuyibing/console/v3/commands/consul/kv/download"
+	"github.com/fuyibing/console/v3/commands/consul/kv/upload"
+	"github.com/fuyibing/console/v3/managers"
+)
+
+const (
+	CmdDesc = "Read and write configuration by consul kv pair"
+	CmdName = "kv"
+)
+
+// New
+// create and return command group.
+//
+//   go run main.go consul kv download
+//   go run main.go consul kv upload
+func New() (managers.Command, error) {
+	return managers.NewCommandGroup(CmdName, CmdDesc,
+		download.New,
+		upload.New,
+	)
+}
@@ -0,0 +1,134 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ConflictHint
+	// printed after conflict error.
+	ConflictHint = "Use --force to override remote changes"
+
+	// LockFilename
+	// stored in config directory, hidden files are not uploaded.
+	LockFilename = ".consul.lock"
+)
+
+// ConflictError
+// returned if remote key changed since download, it's a remote
+// error of managers with hint.
+type ConflictError struct {
+	Hint          string
+	Key           string
+	Local, Remote uint64
+}
+
+// Error
+// return conflict report.
+func (o *ConflictError) Error() string {
+	switch {
+	case o.Local == 0:
+		return fmt.Sprintf("conflict: %s exists on remote but not downloaded, remote index %d", o.Key, o.Remote)
+	case o.Remote == 0:
+		return fmt.Sprintf("conflict: %s removed since download, local index %d", o.Key, o.Local)
+	}
+	return fmt.Sprintf("conflict: %s changed since download, local index %d, remote index %d", o.Key, o.Local, o.Remote)
+}
+
+// GetCode
+// return process exit code.
+func (o *ConflictError) GetCode() int { return managers.ExitCodeRemote }
+
+// GetHint
+// return hint printed after error.
+func (o *ConflictError) GetHint() string {
+	if o.Hint != "" {
+		return o.Hint
+	}
+	return ConflictHint
+}
+
+// GetKind
+// return error kind.
+func (o *ConflictError) GetKind() managers.ErrorKind { return managers.ErrorKindRemote }
+
+// SetHint
+// replace default hint.
+func (o *ConflictError) SetHint(s string) managers.Error { o.Hint = s; return o }
+
+// Lock
+// metadata of downloaded keys, key is consul key name and value
+// is the ModifyIndex seen at download time.
+//
+//   {
+//       "keys": {
+//           "app/myapp": 128
+//       }
+//   }
+type Lock struct {
+	Keys map[string]uint64 `json:"keys"`
+	path string
+}
+
+// LoadLock
+// read lock file in config directory, empty lock returned if file
+// not exists.
+func LoadLock(path string) (lock *Lock, err error) {
+	var (
+		buf      []byte
+		fullPath = filepath.Join(path, LockFilename)
+	)
+
+	lock = &Lock{Keys: make(map[string]uint64), path: fullPath}
+
+	// Read file.
+	if buf, err = os.ReadFile(fullPath); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	// Parse file.
+	if err = json.Unmarshal(buf, lock); err != nil {
+		err = fmt.Errorf("lock file parse failed: %s: %v", fullPath, err)
+		return
+	}
+	if lock.Keys == nil {
+		lock.Keys = make(map[string]uint64)
+	}
+	return
+}
+
+// Delete
+// index of key.
+func (o *Lock) Delete(key string) { delete(o.Keys, key) }
+
+// Get
+// index of key, zero returned if not recorded.
+func (o *Lock) Get(key string) uint64 { return o.Keys[key] }
+
+// Save
+// lock file, parent directories are created.
+func (o *Lock) Save() (err error) {
+	var buf []byte
+
+	if buf, err = json.MarshalIndent(o, "", "    "); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(o.path), os.ModePerm); err == nil {
+		err = os.WriteFile(o.path, buf, 0644)
+	}
+	return
+}
+
+// Set
+// index of key.
+func (o *Lock) Set(key string, index uint64) { o.Keys[key] = index }
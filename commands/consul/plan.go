@@ -0,0 +1,301 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"github.com/hashicorp/consul/api"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeCreate    = "create"
+	ChangeDelete    = "delete"
+	ChangeIgnored   = "ignored"
+	ChangeUnchanged = "unchanged"
+	ChangeUpdate    = "update"
+)
+
+// Change
+// of a file or key computed by plan, nothing written.
+type Change struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// RenderPlan
+// print unified diffs of changes to writer of output if diff enabled,
+// otherwise render status of changes with output manager.
+func RenderPlan(out managers.OutputManager, list []*Change, diff bool, title string) (err error) {
+	if diff {
+		for _, c := range list {
+			if c.Diff != "" {
+				if _, err = io.WriteString(out.GetWriter(), c.Diff); err != nil {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	res := make(map[string]interface{})
+	for _, c := range list {
+		res[c.Name] = c.Status
+	}
+	out.Map(res, title)
+	return
+}
+
+// PlanDownload
+// compare remote configuration with local files and return
+// changes which download would make.
+func (o *ClientManager) PlanDownload(ctx context.Context, cfg *api.Config, key, path string, override, tree bool) (list []*Change, err error) {
+	var (
+		cli           *api.Client
+		local, remote map[string]string
+	)
+
+	// Build
+	// consul api client.
+	if cli, err = api.NewClient(cfg); err != nil {
+		return
+	}
+
+	// Read
+	// remote and local contents.
+	if remote, err = o.planRemote(ctx, cli, key, tree, true); err != nil {
+		return
+	}
+	if local, err = o.planLocal(path, tree); err != nil {
+		return
+	}
+
+	// Compare
+	// local as old and remote as new.
+	for name, text := range remote {
+		var (
+			c        = &Change{Name: filepath.Join(path, filepath.FromSlash(name))}
+			prev, ok = local[name]
+		)
+
+		switch {
+		case !ok:
+			c.Status = ChangeCreate
+		case !override:
+			c.Status = ChangeIgnored
+		default:
+			c.Status = o.planStatus(prev, text, tree)
+		}
+
+		if c.Status == ChangeCreate || c.Status == ChangeUpdate {
+			c.Diff = Diff(o.planText(prev, tree), o.planText(text, tree), "local:"+c.Name, "consul:"+o.planKey(key, name, tree))
+		}
+		list = append(list, c)
+	}
+
+	o.planSort(list)
+	return
+}
+
+// PlanUpload
+// compare local files with remote configuration and return
+// changes which upload would make.
+func (o *ClientManager) PlanUpload(ctx context.Context, cfg *api.Config, key, path string, tree bool) (list []*Change, err error) {
+	var (
+		cli           *api.Client
+		local, remote map[string]string
+	)
+
+	// Build
+	// consul api client.
+	if cli, err = api.NewClient(cfg); err != nil {
+		return
+	}
+
+	// Read
+	// remote and local contents.
+	if remote, err = o.planRemote(ctx, cli, key, tree, false); err != nil {
+		return
+	}
+	if local, err = o.planLocal(path, tree); err != nil {
+		return
+	}
+
+	// Compare
+	// remote as old and local as new.
+	for name, text := range local {
+		var (
+			c        = &Change{Name: o.planKey(key, name, tree)}
+			prev, ok = remote[name]
+		)
+
+		if !ok {
+			c.Status = ChangeCreate
+		} else {
+			c.Status = o.planStatus(prev, text, tree)
+		}
+
+		if c.Status != ChangeUnchanged {
+			c.Diff = Diff(o.planText(prev, tree), o.planText(text, tree), "consul:"+c.Name, "local:"+filepath.Join(path, filepath.FromSlash(name)))
+		}
+		list = append(list, c)
+	}
+
+	// Removed files
+	// of whole key, or keys deleted in tree mode.
+	for name, prev := range remote {
+		if _, ok := local[name]; !ok && !(tree && o.treeHidden(name)) {
+			c := &Change{Name: o.planKey(key, name, tree), Status: ChangeDelete}
+			c.Diff = Diff(o.planText(prev, tree), "", "consul:"+c.Name, "/dev/null")
+			list = append(list, c)
+		}
+	}
+
+	o.planSort(list)
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and construct methods
+// /////////////////////////////////////////////////////////////
+
+// Display key
+// of file, such as: app/myapp:db.yaml or app/myapp/db.yaml.
+func (o *ClientManager) planKey(key, name string, tree bool) string {
+	if tree {
+		return o.treePrefix(key) + name
+	}
+	return fmt.Sprintf("%s:%s", key, name)
+}
+
+// Read
+// local files, key is relative name with slash separator.
+func (o *ClientManager) planLocal(path string, tree bool) (files map[string]string, err error) {
+	var (
+		buf   []byte
+		ds    []os.DirEntry
+		names map[string]string
+	)
+
+	files = make(map[string]string)
+
+	// Files of tree.
+	if tree {
+		if names, err = o.treeFiles(path); err != nil {
+			return
+		}
+		for name, fullPath := range names {
+			if buf, err = os.ReadFile(fullPath); err != nil {
+				return
+			}
+			files[name] = string(buf)
+		}
+		return
+	}
+
+	// Yaml files
+	// in directory.
+	if ds, err = os.ReadDir(path); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	for _, d := range ds {
+		if d.IsDir() || !RegexFilename.MatchString(d.Name()) {
+			continue
+		}
+		if buf, err = os.ReadFile(filepath.Join(path, d.Name())); err != nil {
+			return
+		}
+		files[d.Name()] = string(buf)
+	}
+	return
+}
+
+// Read
+// remote files, key is relative name with slash separator.
+func (o *ClientManager) planRemote(ctx context.Context, c *api.Client, key string, tree, resolve bool) (files map[string]string, err error) {
+	var (
+		kp    *api.KVPair
+		pairs api.KVPairs
+		text  string
+	)
+
+	files = make(map[string]string)
+
+	// Pairs of tree.
+	if tree {
+		prefix := o.treePrefix(key)
+		if pairs, _, err = c.KV().List(prefix, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+			err = managers.NewRemoteError(err)
+			return
+		}
+		for _, p := range pairs {
+			if !strings.HasSuffix(p.Key, "/") {
+				files[strings.TrimPrefix(p.Key, prefix)] = string(p.Value)
+			}
+		}
+		return
+	}
+
+	// Value of key, variables
+	// are replaced if resolve enabled.
+	if resolve {
+		if text, _, err = o.keyReader(ctx, c, make(map[string]interface{}), key); err != nil {
+			return
+		}
+	} else {
+		if kp, _, err = c.KV().Get(key, (&api.QueryOptions{}).WithContext(ctx)); err != nil || kp == nil {
+			err = managers.NewRemoteError(err)
+			return
+		}
+		text = string(kp.Value)
+	}
+
+	// Split
+	// as files.
+	for _, rows := range o.keySplit(text) {
+		files[rows[0]] = strings.Join(rows[1:], "\n")
+	}
+	return
+}
+
+func (o *ClientManager) planSort(list []*Change) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+}
+
+// Compare
+// old and new contents.
+func (o *ClientManager) planStatus(prev, text string, tree bool) string {
+	if o.planText(prev, tree) == o.planText(text, tree) {
+		return ChangeUnchanged
+	}
+	return ChangeUpdate
+}
+
+// Normalize text, empty lines
+// are not stored in single key mode.
+func (o *ClientManager) planText(text string, tree bool) string {
+	if tree {
+		return text
+	}
+
+	list := make([]string, 0)
+	for _, s := range strings.Split(text, "\n") {
+		if strings.TrimSpace(s) != "" {
+			list = append(list, strings.TrimRight(s, "\r"))
+		}
+	}
+	return strings.Join(list, "\n")
+}
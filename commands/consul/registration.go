@@ -0,0 +1,118 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/hcl"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadRegistration
+// read service definition file, accept: .hcl, .json, .yaml, .yml.
+// Definition can be wrapped by service key as consul agent config
+// and keys can be snake case.
+//
+//   service {
+//     name = "myapp"
+//     port = 8080
+//     tags = ["v1"]
+//     check {
+//       http     = "http://127.0.0.1:8080/health"
+//       interval = "10s"
+//     }
+//   }
+func LoadRegistration(path string) (req *api.AgentServiceRegistration, err error) {
+	var (
+		buf  []byte
+		data = make(map[string]interface{})
+	)
+
+	// Read file.
+	if buf, err = os.ReadFile(path); err != nil {
+		return
+	}
+
+	// Parse
+	// by file extension.
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".hcl":
+		err = hcl.Unmarshal(buf, &data)
+	case ".json":
+		err = json.Unmarshal(buf, &data)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buf, &data)
+	default:
+		err = fmt.Errorf("service file type not supported: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service file parse failed: %s: %v", path, err)
+	}
+
+	// Unwrap
+	// service key.
+	value := registrationNormalize("", data)
+	if m, ok := value.(map[string]interface{}); ok {
+		if s, ok := m["service"]; ok {
+			value = s
+		}
+	}
+
+	// Convert
+	// to registration.
+	if buf, err = json.Marshal(value); err == nil {
+		req = &api.AgentServiceRegistration{}
+		if err = json.Unmarshal(buf, req); err != nil {
+			return nil, fmt.Errorf("service file parse failed: %s: %v", path, err)
+		}
+	}
+	return
+}
+
+// Normalize keys
+// to match field names of api, underscores are removed and single
+// hcl blocks are flattened. Keys of free maps are not changed.
+func registrationNormalize(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if key == "header" || key == "meta" || key == "taggedaddresses" {
+			return v
+		}
+		res := make(map[string]interface{})
+		for k, x := range v {
+			k = strings.ToLower(strings.ReplaceAll(k, "_", ""))
+			if k == "args" {
+				k = "scriptargs"
+			}
+			res[k] = registrationNormalize(k, x)
+		}
+		return res
+	case []map[string]interface{}:
+		list := make([]interface{}, 0)
+		for _, x := range v {
+			list = append(list, x)
+		}
+		return registrationNormalize(key, list)
+	case []interface{}:
+		list := make([]interface{}, 0)
+		for _, x := range v {
+			list = append(list, registrationNormalize(key, x))
+		}
+
+		// Flatten
+		// single hcl block, such as: check { ... }.
+		if key != "checks" && len(list) == 1 {
+			if m, ok := list[0].(map[string]interface{}); ok {
+				return m
+			}
+		}
+		return list
+	}
+	return value
+}
@@ -6,64 +6,64 @@
 package deregister
 
 import (
+	"context"
 	"fmt"
 	"github.com/fuyibing/console/v3/commands/consul"
 	"github.com/fuyibing/console/v3/managers"
-	"github.com/hashicorp/consul/api"
 )
 
 const (
 	CmdDesc = "Remove service from consul"
-	CmdName = "service:deregister"
+	CmdName = "deregister"
 )
 
-type Command struct {
-	Command managers.Command
-	Err     error
-	Name    string
-}
-
-// Handle
-// send upload request.
-func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
-	var (
-		cfg                    = api.DefaultNonPooledConfig()
-		keys                   map[string]interface{}
-		serviceId, serviceName string
-	)
+type (
+	// Command
+	// for consul service deregister.
+	Command struct {
+		Command managers.Command
+		Err     error
+		Name    string
+		Params  *Params
+	}
 
-	// Read address option.
-	//
-	//   -a consul.example.com
-	//   --addr consul.example.com
-	//   --addr="consul.example.com"
-	if cfg.Address, err = o.Command.GetOption(consul.OptAddr).ToString(); err != nil {
-		return
+	// Options
+	// declared by command.
+	Options struct {
+		ServiceId   string `console:"service-id,,required" desc:"Consul service id, such as: myapp-hash"`
+		ServiceName string `console:"service-name,,required" desc:"Consul service name, such as: myapp"`
 	}
 
-	// Read scheme option.
-	//
-	//   -s https
-	//   --scheme https
-	//   --scheme="https"
-	if cfg.Scheme, err = o.Command.GetOption(consul.OptScheme).ToString(); err != nil {
-		return
+	// Params
+	// bound with command options, consul config options are
+	// inherited from consul command group.
+	Params struct {
+		consul.Config
+		Options
 	}
+)
 
-	// Service id.
-	if serviceId, err = o.Command.GetOption(consul.OptServiceId).ToString(); err != nil {
+// Handle
+// send deregister request.
+func (o *Command) Handle(ctx context.Context, _ managers.Manager, a managers.Arguments) (err error) {
+	var (
+		keys map[string]interface{}
+		p    = &Params{}
+	)
+
+	// Read options
+	// of invocation, config options must be inherited.
+	if err = consul.CheckConfig(a); err != nil {
 		return
 	}
-
-	// Service name.
-	if serviceName, err = o.Command.GetOption(consul.OptServiceName).ToString(); err != nil {
+	if err = a.Populate(p); err != nil {
 		return
 	}
 
 	// Send
 	// deregister request.
-	keys, err = consul.Client.Deregister(cfg, serviceName, serviceId)
-	managers.Output.Map(keys, fmt.Sprintf("Remove service: %v", serviceName))
+	keys, err = consul.Client.Deregister(ctx, p.ApiConfig(), p.ServiceName, p.ServiceId)
+	a.GetOutput().Map(keys, fmt.Sprintf("Remove service: %v", p.ServiceName))
 	return
 }
 
@@ -71,32 +71,27 @@ func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
 // initialize command fields.
 func (o *Command) InitField() *Command {
 	o.Command = managers.NewCommand(o.Name)
-	o.Command.SetDescription(CmdDesc).SetHandler(o.Handle)
+	o.Command.SetDescription(CmdDesc).SetContextHandler(o.Handle)
 	return o
 }
 
 // InitOption
 // initialize command option.
 func (o *Command) InitOption() *Command {
-	o.Err = o.Command.AddOption(
-		managers.NewOption(consul.OptAddr).SetShortName(consul.OptAddrByte).SetDescription(consul.OptAddrDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptScheme).SetShortName(consul.OptSchemeByte).SetDescription(consul.OptSchemeDesc).SetDefault(consul.OptSchemeDefault),
-		managers.NewOption(consul.OptServiceId).SetDescription(consul.OptServiceIdDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptServiceName).SetDescription(consul.OptServiceNameDesc).SetMode(managers.ModeRequired),
-	)
+	o.Err = o.Command.BindStruct(&o.Params.Options)
 	return o
 }
 
 // New
 // create and return instance.
 //
-//   go run main.go service:deregister \
+//   go run main.go consul service deregister \
 //     --addr=consul.example.com \
 //     --scheme=https \
 //     --service-id=myapp-hash-string \
 //     --service-name=myapp
 func New() (managers.Command, error) {
-	o := (&Command{Name: CmdName}).
+	o := (&Command{Name: CmdName, Params: &Params{}}).
 		InitField().
 		InitOption()
 
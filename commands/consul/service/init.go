@@ -4,3 +4,26 @@
 // Package service
 // register and deregister service on consul service storage.
 package service
+
+import (
+	"github.com/fuyibing/console/v3/commands/consul/service/deregister"
+	"github.com/fuyibing/console/v3/commands/consul/service/register"
+	"github.com/fuyibing/console/v3/managers"
+)
+
+const (
+	CmdDesc = "Register and deregister service on consul"
+	CmdName = "service"
+)
+
+// New
+// create and return command group.
+//
+//   go run main.go consul service register
+//   go run main.go consul service deregister
+func New() (managers.Command, error) {
+	return managers.NewCommandGroup(CmdName, CmdDesc,
+		deregister.New,
+		register.New,
+	)
+}
@@ -6,77 +6,162 @@
 package register
 
 import (
+	"context"
 	"fmt"
 	"github.com/fuyibing/console/v3/commands/consul"
 	"github.com/fuyibing/console/v3/managers"
 	"github.com/hashicorp/consul/api"
+	"strings"
+	"time"
 )
 
 const (
 	CmdDesc = "Register service to consul"
-	CmdName = "service:register"
+	CmdName = "register"
 )
 
-type Command struct {
-	Command managers.Command
-	Err     error
-	Name    string
-}
+type (
+	// Command
+	// for consul service register.
+	Command struct {
+		Command managers.Command
+		Err     error
+		Name    string
+		Params  *Params
+	}
+
+	// Options
+	// declared by command.
+	Options struct {
+		File              string            `console:"file,f" desc:"Service definition file, accept: .hcl, .json, .yaml, .yml" type:"file"`
+		ServiceAddr       string            `console:"service-addr" desc:"Consul service address, such as: 172.16.0.100, app.example.com"`
+		ServiceId         string            `console:"service-id" desc:"Consul service id, such as: myapp-hash"`
+		ServiceName       string            `console:"service-name" desc:"Consul service name, such as: myapp"`
+		ServicePort       int               `console:"service-port" desc:"Consul service port, such as: 80, 8080" min:"1" max:"65535"`
+		Tag               []string          `console:"tag" desc:"Service tag, repeatable or separated by comma, such as: --tag v1 --tag primary"`
+		Meta              map[string]string `console:"meta" desc:"Service meta pair, repeatable or separated by comma, such as: --meta version=1 --meta env=prod"`
+		EnableTagOverride bool              `console:"enable-tag-override" desc:"Allow tags to be updated by external agents" default:"false"`
+
+		CheckHttp       string        `console:"check-http" desc:"HTTP health check url, such as: http://127.0.0.1:8080/health"`
+		CheckTcp        string        `console:"check-tcp" desc:"TCP health check address, such as: 127.0.0.1:8080" type:"host:port"`
+		CheckGrpc       string        `console:"check-grpc" desc:"gRPC health check address, such as: 127.0.0.1:9090/myapp"`
+		CheckTtl        time.Duration `console:"check-ttl" desc:"TTL health check duration, such as: 30s"`
+		CheckScript     string        `console:"check-script" desc:"Script health check command, arguments separated by space"`
+		CheckInterval   time.Duration `console:"check-interval" desc:"Health check interval" default:"10s"`
+		CheckTimeout    time.Duration `console:"check-timeout" desc:"Health check timeout, such as: 5s"`
+		CheckDeregister time.Duration `console:"check-deregister-after" desc:"Deregister service if health check critical for duration, such as: 1m"`
+	}
+
+	// Params
+	// bound with command options, consul config options are
+	// inherited from consul command group.
+	Params struct {
+		consul.Config
+		Options
+	}
+)
 
 // Handle
-// send upload request.
-func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
+// send register request.
+func (o *Command) Handle(ctx context.Context, _ managers.Manager, a managers.Arguments) (err error) {
 	var (
-		cfg  = api.DefaultNonPooledConfig()
 		keys map[string]interface{}
-		port int64
-		req  = &api.AgentServiceRegistration{}
+		p    = &Params{}
+		req  *api.AgentServiceRegistration
 	)
 
-	// Read address option.
-	//
-	//   -a consul.example.com
-	//   --addr consul.example.com
-	//   --addr="consul.example.com"
-	if cfg.Address, err = o.Command.GetOption(consul.OptAddr).ToString(); err != nil {
+	// Read options
+	// of invocation, config options must be inherited.
+	if err = consul.CheckConfig(a); err != nil {
 		return
 	}
-
-	// Read scheme option.
-	//
-	//   -s https
-	//   --scheme https
-	//   --scheme="https"
-	if cfg.Scheme, err = o.Command.GetOption(consul.OptScheme).ToString(); err != nil {
+	if err = a.Populate(p); err != nil {
 		return
 	}
 
-	// Service address.
-	if req.Address, err = o.Command.GetOption(consul.OptServiceAddr).ToString(); err != nil {
+	// Build
+	// registration request.
+	if req, err = p.Registration(); err != nil {
 		return
 	}
 
-	// Service id.
-	if req.ID, err = o.Command.GetOption(consul.OptServiceId).ToString(); err != nil {
-		return
+	// Send
+	// register request.
+	keys, err = consul.Client.Register(ctx, p.ApiConfig(), req)
+	a.GetOutput().Map(keys, fmt.Sprintf("Register service: %s", req.Name))
+	return
+}
+
+// Registration
+// build registration with definition file and options, option
+// values override file values and checks are appended.
+func (o *Params) Registration() (req *api.AgentServiceRegistration, err error) {
+	// Load
+	// definition file.
+	if o.File != "" {
+		if req, err = consul.LoadRegistration(o.File); err != nil {
+			return
+		}
+	} else {
+		req = &api.AgentServiceRegistration{}
 	}
 
-	// Service name.
-	if req.Name, err = o.Command.GetOption(consul.OptServiceName).ToString(); err != nil {
-		return
+	// Service fields.
+	if o.ServiceAddr != "" {
+		req.Address = o.ServiceAddr
+	}
+	if o.ServiceId != "" {
+		req.ID = o.ServiceId
+	}
+	if o.ServiceName != "" {
+		req.Name = o.ServiceName
+	}
+	if o.ServicePort > 0 {
+		req.Port = o.ServicePort
+	}
+	if o.EnableTagOverride {
+		req.EnableTagOverride = true
 	}
 
-	// Service port.
-	if port, err = o.Command.GetOption(consul.OptServicePort).ToInt(); err != nil {
-		return
-	} else {
-		req.Port = int(port)
+	// Tags.
+	if len(o.Tag) > 0 {
+		req.Tags = append([]string{}, o.Tag...)
 	}
 
-	// Send
-	// register request.
-	keys, err = consul.Client.Register(cfg, req)
-	managers.Output.Map(keys, fmt.Sprintf("Register service: %s", req.Name))
+	// Meta pairs.
+	if len(o.Meta) > 0 {
+		if req.Meta == nil {
+			req.Meta = make(map[string]string)
+		}
+		for k, v := range o.Meta {
+			req.Meta[k] = v
+		}
+	}
+
+	// Health checks.
+	for _, c := range []*api.AgentServiceCheck{
+		{HTTP: o.CheckHttp},
+		{TCP: o.CheckTcp},
+		{GRPC: o.CheckGrpc},
+		{TTL: durationText(o.CheckTtl)},
+		{Args: strings.Fields(o.CheckScript)},
+	} {
+		if c.HTTP == "" && c.TCP == "" && c.GRPC == "" && c.TTL == "" && len(c.Args) == 0 {
+			continue
+		}
+		if c.TTL == "" {
+			c.Interval = durationText(o.CheckInterval)
+			c.Timeout = durationText(o.CheckTimeout)
+		}
+		c.DeregisterCriticalServiceAfter = durationText(o.CheckDeregister)
+		req.Checks = append(req.Checks, c)
+	}
+
+	// Return error
+	// if service name not specified.
+	if req.Name == "" {
+		err = managers.NewUsageError("service name is required").SetHint("Specify service name with --service-name or --file")
+	}
 	return
 }
 
@@ -84,38 +169,48 @@ func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
 // initialize command fields.
 func (o *Command) InitField() *Command {
 	o.Command = managers.NewCommand(o.Name)
-	o.Command.SetDescription(CmdDesc).SetHandler(o.Handle)
+	o.Command.SetDescription(CmdDesc).SetContextHandler(o.Handle)
 	return o
 }
 
 // InitOption
 // initialize command option.
 func (o *Command) InitOption() *Command {
-	o.Err = o.Command.AddOption(
-		managers.NewOption(consul.OptAddr).SetShortName(consul.OptAddrByte).SetDescription(consul.OptAddrDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptScheme).SetShortName(consul.OptSchemeByte).SetDescription(consul.OptSchemeDesc).SetDefault(consul.OptSchemeDefault),
-		managers.NewOption(consul.OptServiceAddr).SetDescription(consul.OptServiceAddrDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptServiceId).SetDescription(consul.OptServiceIdDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptServiceName).SetDescription(consul.OptServiceNameDesc).SetMode(managers.ModeRequired),
-		managers.NewOption(consul.OptServicePort).SetDescription(consul.OptServicePortDesc).SetMode(managers.ModeRequired).SetValueType(managers.ValueTypeInteger),
-	)
+	o.Err = o.Command.BindStruct(&o.Params.Options)
 	return o
 }
 
 // New
 // create and return instance.
 //
-//   go run main.go service:register \
+//   go run main.go consul service register \
 //     --addr=consul.example.com \
 //     --scheme=https \
 //     --service-addr=127.0.0.1 \
 //     --service-port=8080 \
 //     --service-id=myapp-hash-string \
-//     --service-name=myapp
+//     --service-name=myapp \
+//     --tag=v1,primary \
+//     --meta=version=1 \
+//     --check-http=http://127.0.0.1:8080/health \
+//     --check-deregister-after=1m
+//
+//   go run main.go consul service register \
+//     --addr=consul.example.com \
+//     --file=./service.hcl
 func New() (managers.Command, error) {
-	o := (&Command{Name: CmdName}).
+	o := (&Command{Name: CmdName, Params: &Params{}}).
 		InitField().
 		InitOption()
 
 	return o.Command, o.Err
 }
+
+// Duration text
+// accepted by consul, empty if not specified.
+func durationText(d time.Duration) string {
+	if d > 0 {
+		return d.String()
+	}
+	return ""
+}
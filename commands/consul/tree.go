@@ -0,0 +1,374 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"github.com/hashicorp/consul/api"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DownloadTree
+// read all keys under prefix from consul and store each key as
+// a local file, key segments become directories. ModifyIndex of
+// saved keys are recorded in lock file.
+//
+//   app/myapp/db.yaml         -> ./config/db.yaml
+//   app/myapp/redis/main.yaml -> ./config/redis/main.yaml
+func (o *ClientManager) DownloadTree(ctx context.Context, cfg *api.Config, prefix, path string, override bool) (res map[string]interface{}, err error) {
+	var (
+		cli      *api.Client
+		fullPath string
+		lock     *Lock
+		pairs    api.KVPairs
+		saved    bool
+		unsafe   int
+	)
+
+	// Prepare
+	// download results.
+	res = make(map[string]interface{})
+	prefix = o.treePrefix(prefix)
+
+	// Build
+	// consul api client.
+	if cli, err = api.NewClient(cfg); err != nil {
+		return
+	}
+
+	// Read lock
+	// of config directory.
+	if lock, err = LoadLock(path); err != nil {
+		return
+	}
+
+	// Save lock
+	// with index of saved keys when end.
+	defer func() {
+		if se := lock.Save(); err == nil {
+			err = se
+		}
+	}()
+
+	// List pairs
+	// under prefix.
+	if pairs, _, err = cli.KV().List(prefix, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+		err = managers.NewRemoteError(err)
+		res[prefix] = err
+		return
+	}
+
+	// Range pairs.
+	for _, kp := range pairs {
+		// Ignore folder key.
+		if strings.HasSuffix(kp.Key, "/") {
+			continue
+		}
+
+		// Skip key
+		// if file path escapes from config directory.
+		if fullPath, err = o.treePath(path, strings.TrimPrefix(kp.Key, prefix)); err != nil {
+			res[kp.Key] = err
+			unsafe++
+			continue
+		}
+
+		// Save
+		// to relative file.
+		if saved, err = o.treeSave(res, override, fullPath, kp.Value); err != nil {
+			return
+		}
+		if saved {
+			lock.Set(kp.Key, kp.ModifyIndex)
+		}
+	}
+
+	// Return error
+	// if any key skipped.
+	if err = nil; unsafe > 0 {
+		err = managers.NewValidationError("unsafe: %d key(s) skipped, path outside of %s", unsafe, path)
+	}
+	return
+}
+
+// UploadTree
+// read all files in local directory and put each file as a key
+// under prefix, directories become key segments. Hidden files
+// and directories are ignored. Remote keys under prefix without
+// local file are deleted, so prefix mirrors directory. Each key
+// is put or deleted with check-and-set against lock file,
+// conflicted keys are reported and skipped.
+//
+//   ./config/db.yaml         -> app/myapp/db.yaml
+//   ./config/redis/main.yaml -> app/myapp/redis/main.yaml
+//   (removed)                -> app/myapp/redis/slave.yaml deleted
+func (o *ClientManager) UploadTree(ctx context.Context, cfg *api.Config, prefix, path string, force bool) (res map[string]interface{}, err error) {
+	var (
+		cli       *api.Client
+		conflicts int
+		files     map[string]string
+		keys      []string
+		lock      *Lock
+	)
+
+	// Prepare
+	// uploaded results.
+	res = make(map[string]interface{})
+	prefix = o.treePrefix(prefix)
+
+	// Build
+	// consul api client.
+	if cli, err = api.NewClient(cfg); err != nil {
+		return
+	}
+
+	// Read lock
+	// of config directory.
+	if lock, err = LoadLock(path); err != nil {
+		return
+	}
+
+	// Save lock
+	// with refreshed index when end.
+	defer func() {
+		if se := lock.Save(); err == nil {
+			err = se
+		}
+	}()
+
+	// Read
+	// local files.
+	if files, err = o.treeFiles(path); err != nil {
+		res[path] = err
+		return
+	}
+
+	// Range files
+	// sorted by name.
+	for _, name := range o.treeNames(files) {
+		// Stop
+		// if context cancelled.
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if err = o.treePut(ctx, cli, res, lock, prefix+name, files[name], force); err != nil {
+			if _, ok := err.(*ConflictError); !ok {
+				return
+			}
+			conflicts++
+		}
+	}
+
+	// List keys
+	// under prefix.
+	if keys, _, err = cli.KV().Keys(prefix, "", (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+		err = managers.NewRemoteError(err)
+		res[prefix] = err
+		return
+	}
+
+	// Delete keys
+	// without local file.
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		if _, ok := files[name]; ok || strings.HasSuffix(key, "/") || o.treeHidden(name) {
+			continue
+		}
+
+		if err = o.treeDelete(ctx, cli, res, lock, key, force); err != nil {
+			if _, ok := err.(*ConflictError); !ok {
+				return
+			}
+			conflicts++
+		}
+	}
+
+	// Return error
+	// if any key conflicted.
+	if err = nil; conflicts > 0 {
+		err = managers.NewError(managers.ErrorKindRemote, "conflict: %d key(s) changed since download", conflicts).SetHint(ConflictHint)
+	}
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and construct methods
+// /////////////////////////////////////////////////////////////
+
+// Local file path
+// of relative key name, return error if cleaned name is absolute
+// or outside of config directory.
+//
+//   app/myapp/../../evil.txt -> error
+func (o *ClientManager) treePath(path, name string) (string, error) {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("key path outside of config directory: %s", name)
+	}
+	return filepath.Join(path, name), nil
+}
+
+// Normalize prefix
+// with slash suffix.
+func (o *ClientManager) treePrefix(prefix string) string {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// Delete key
+// from consul with check-and-set against lock file, key is deleted
+// only if not changed since download, or force enabled.
+func (o *ClientManager) treeDelete(ctx context.Context, c *api.Client, res map[string]interface{}, lock *Lock, key string, force bool) (err error) {
+	var (
+		kp *api.KVPair
+		ok bool
+		wo = (&api.WriteOptions{}).WithContext(ctx)
+	)
+
+	defer func() {
+		if err != nil {
+			res[key] = err
+		} else {
+			res[key] = "deleted"
+		}
+	}()
+
+	// Send delete request.
+	if force {
+		_, err = c.KV().Delete(key, wo)
+	} else if ok, _, err = c.KV().DeleteCAS(&api.KVPair{Key: key, ModifyIndex: lock.Get(key)}, wo); err == nil && !ok {
+		ce := &ConflictError{Key: key, Local: lock.Get(key)}
+		if kp, _, err = c.KV().Get(key, (&api.QueryOptions{}).WithContext(ctx)); err == nil {
+			if kp != nil {
+				ce.Remote = kp.ModifyIndex
+			}
+			err = ce
+		}
+	}
+	if err != nil {
+		err = managers.WrapError(managers.ErrorKindRemote, err)
+		return
+	}
+
+	lock.Delete(key)
+	return
+}
+
+// Hidden
+// name which is ignored as local file, any segment starts with dot.
+//
+//   .git/config, redis/.main.yaml
+func (o *ClientManager) treeHidden(name string) bool {
+	for _, s := range strings.Split(name, "/") {
+		if strings.HasPrefix(s, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Read local files
+// of directory, key is relative name with slash separator and
+// value is full path. Hidden files and directories are ignored.
+func (o *ClientManager) treeFiles(path string) (files map[string]string, err error) {
+	files = make(map[string]string)
+	err = filepath.WalkDir(path, func(fullPath string, d fs.DirEntry, we error) error {
+		if we != nil {
+			return we
+		}
+
+		// Ignore
+		// hidden file or directory.
+		if fullPath != path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Collect file.
+		if !d.IsDir() {
+			rel, re := filepath.Rel(path, fullPath)
+			if re != nil {
+				return re
+			}
+			files[filepath.ToSlash(rel)] = fullPath
+		}
+		return nil
+	})
+	return
+}
+
+// Sorted names
+// of files.
+func (o *ClientManager) treeNames(files map[string]string) []string {
+	list := make([]string, 0)
+	for name := range files {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Put file
+// contents to consul.
+func (o *ClientManager) treePut(ctx context.Context, c *api.Client, res map[string]interface{}, lock *Lock, key, fullPath string, force bool) (err error) {
+	var (
+		buf []byte
+	)
+
+	defer func() {
+		if err != nil {
+			res[fullPath] = err
+		} else {
+			res[fullPath] = fmt.Sprintf("succeed: %s", key)
+		}
+	}()
+
+	// Read file
+	// and send put request.
+	if buf, err = os.ReadFile(fullPath); err == nil {
+		err = o.keyPut(ctx, c, lock, key, buf, force)
+	}
+	return
+}
+
+// Save value
+// to local file, parent directories are created. Saved is false
+// if file exists and not override.
+func (o *ClientManager) treeSave(res map[string]interface{}, override bool, fullPath string, value []byte) (saved bool, err error) {
+	// Not override.
+	if !override {
+		if s, se := os.Stat(fullPath); se == nil && !s.IsDir() {
+			res[fullPath] = "ignored"
+			return
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			res[fullPath] = err
+		} else {
+			res[fullPath] = "succeed"
+		}
+	}()
+
+	// Create
+	// parent directories.
+	if err = os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err == nil {
+		if err = os.WriteFile(fullPath, value, os.ModePerm); err == nil {
+			saved = true
+		}
+	}
+	return
+}
@@ -0,0 +1,192 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package consul
+
+import (
+	"context"
+	"github.com/fuyibing/console/v3/commands/consul/consultest"
+	"github.com/fuyibing/console/v3/managers"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientDownloadTree(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	db := srv.Put("app/myapp/db.yaml", "host: 127.0.0.1")
+	srv.Put("app/myapp/redis/main.yaml", "port: 6379")
+	srv.Put("app/other/db.yaml", "host: 10.0.0.1")
+
+	if _, err := Client.DownloadTree(ctx, srv.Config(), "app/myapp", path, false); err != nil {
+		t.Fatalf("download tree failed: %v", err)
+	}
+	if s := readFile(t, filepath.Join(path, "db.yaml")); s != "host: 127.0.0.1" {
+		t.Errorf("db.yaml contents: %q", s)
+	}
+	if s := readFile(t, filepath.Join(path, "redis", "main.yaml")); s != "port: 6379" {
+		t.Errorf("redis/main.yaml contents: %q", s)
+	}
+	if lock, _ := LoadLock(path); lock.Get("app/myapp/db.yaml") != db {
+		t.Errorf("lock index: %d, expect: %d", lock.Get("app/myapp/db.yaml"), db)
+	}
+}
+
+func TestClientDownloadTreeTraversal(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "a", "b")
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	srv.Put("pre/db.yaml", "host: 127.0.0.1")
+	srv.Put("pre/../../evil.txt", "evil")
+
+	res, err := Client.DownloadTree(ctx, srv.Config(), "pre", path, false)
+	if code := managers.ErrorCode(err); code != managers.ExitCodeValidation {
+		t.Fatalf("exit code: %d, expect: %d, error: %v", code, managers.ExitCodeValidation, err)
+	}
+	if _, ok := res["pre/../../evil.txt"].(error); !ok {
+		t.Errorf("unsafe key not reported: %v", res)
+	}
+	if _, se := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(se) {
+		t.Errorf("file written outside of path: %v", se)
+	}
+	if s := readFile(t, filepath.Join(path, "db.yaml")); s != "host: 127.0.0.1" {
+		t.Errorf("db.yaml contents: %q", s)
+	}
+}
+
+func TestClientDownloadTreeSaveFailed(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	db := srv.Put("app/myapp/db.yaml", "host: 127.0.0.1")
+	srv.Put("app/myapp/redis.yaml", "port: 6379")
+
+	// Directory
+	// at file path, write of key failed.
+	if err := os.MkdirAll(filepath.Join(path, "redis.yaml"), os.ModePerm); err != nil {
+		t.Fatalf("create directory failed: %v", err)
+	}
+
+	if _, err := Client.DownloadTree(ctx, srv.Config(), "app/myapp", path, true); err == nil {
+		t.Fatalf("error expected for write failed")
+	}
+	if lock, _ := LoadLock(path); lock.Get("app/myapp/db.yaml") != db {
+		t.Errorf("lock index: %d, expect: %d", lock.Get("app/myapp/db.yaml"), db)
+	}
+}
+
+func TestClientUploadTree(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	writeFile(t, filepath.Join(path, "db.yaml"), "host: 127.0.0.1")
+	writeFile(t, filepath.Join(path, "redis", "main.yaml"), "port: 6379")
+	writeFile(t, filepath.Join(path, ".hidden", "ignored.yaml"), "ignored")
+
+	if _, err := Client.UploadTree(ctx, srv.Config(), "app/myapp", path, false); err != nil {
+		t.Fatalf("upload tree failed: %v", err)
+	}
+
+	keys := srv.Keys()
+	if len(keys) != 2 || keys[0] != "app/myapp/db.yaml" || keys[1] != "app/myapp/redis/main.yaml" {
+		t.Fatalf("remote keys: %v", keys)
+	}
+	if s, _ := srv.Get("app/myapp/redis/main.yaml"); s != "port: 6379" {
+		t.Errorf("remote value: %q", s)
+	}
+
+	// Conflict
+	// if changed by other writer, force override.
+	srv.Put("app/myapp/db.yaml", "host: 10.0.0.1")
+
+	_, err := Client.UploadTree(ctx, srv.Config(), "app/myapp", path, false)
+	if err == nil {
+		t.Fatalf("conflict error expected")
+	}
+	if s, _ := srv.Get("app/myapp/db.yaml"); s != "host: 10.0.0.1" {
+		t.Errorf("remote value overwritten: %q", s)
+	}
+	if _, err = Client.UploadTree(ctx, srv.Config(), "app/myapp", path, true); err != nil {
+		t.Fatalf("force upload tree failed: %v", err)
+	}
+	if s, _ := srv.Get("app/myapp/db.yaml"); s != "host: 127.0.0.1" {
+		t.Errorf("remote value not overwritten: %q", s)
+	}
+}
+
+func TestClientUploadTreeDelete(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = t.TempDir()
+		srv  = consultest.NewServer()
+	)
+	defer srv.Close()
+
+	srv.Put("app/myapp/db.yaml", "host: 127.0.0.1")
+	srv.Put("app/myapp/redis/main.yaml", "port: 6379")
+	srv.Put("app/myapp/.hidden/kept.yaml", "kept")
+	if _, err := Client.DownloadTree(ctx, srv.Config(), "app/myapp", path, false); err != nil {
+		t.Fatalf("download tree failed: %v", err)
+	}
+
+	// Removed
+	// local file and key created by other writer.
+	if err := os.Remove(filepath.Join(path, "redis", "main.yaml")); err != nil {
+		t.Fatalf("remove file failed: %v", err)
+	}
+	srv.Put("app/myapp/other.yaml", "other")
+
+	list, err := Client.PlanUpload(ctx, srv.Config(), "app/myapp", path, true)
+	if err != nil {
+		t.Fatalf("plan upload failed: %v", err)
+	}
+	status := make(map[string]string)
+	for _, c := range list {
+		status[c.Name] = c.Status
+	}
+	if len(status) != 3 || status["app/myapp/db.yaml"] != ChangeUnchanged || status["app/myapp/redis/main.yaml"] != ChangeDelete || status["app/myapp/other.yaml"] != ChangeDelete {
+		t.Errorf("plan: %v", status)
+	}
+
+	// Conflict
+	// on key not downloaded, deleted if force.
+	res, err := Client.UploadTree(ctx, srv.Config(), "app/myapp", path, false)
+	if err == nil {
+		t.Fatalf("conflict error expected")
+	}
+	if res["app/myapp/redis/main.yaml"] != "deleted" {
+		t.Errorf("delete result: %v", res["app/myapp/redis/main.yaml"])
+	}
+	if keys := srv.Keys(); len(keys) != 3 || keys[0] != "app/myapp/.hidden/kept.yaml" || keys[1] != "app/myapp/db.yaml" || keys[2] != "app/myapp/other.yaml" {
+		t.Fatalf("remote keys: %v", keys)
+	}
+	if lock, _ := LoadLock(path); lock.Get("app/myapp/redis/main.yaml") != 0 {
+		t.Errorf("lock index of deleted key: %d", lock.Get("app/myapp/redis/main.yaml"))
+	}
+
+	if _, err = Client.UploadTree(ctx, srv.Config(), "app/myapp", path, true); err != nil {
+		t.Fatalf("force upload tree failed: %v", err)
+	}
+	if keys := srv.Keys(); len(keys) != 2 || keys[0] != "app/myapp/.hidden/kept.yaml" || keys[1] != "app/myapp/db.yaml" {
+		t.Errorf("remote keys: %v", keys)
+	}
+}
@@ -6,7 +6,7 @@
 package docs
 
 import (
-	"fmt"
+	"context"
 	"github.com/fuyibing/console/v3/managers"
 	"github.com/fuyibing/gdoc/adapters/markdown"
 	"github.com/fuyibing/gdoc/adapters/postman"
@@ -19,7 +19,14 @@ import (
 const (
 	CmdDesc = "Generate application documents as markdown files or postman collection and so on"
 	CmdName = "docs"
+)
 
+// Option names
+// of docs command.
+//
+// Deprecated: options are declared by struct tags of Params,
+// constants are kept for compatibility only.
+const (
 	OptAdapter        = "adapter"
 	OptAdapterByte    = 'a'
 	OptAdapterDesc    = "Specify document formatter, accept: postman, markdown"
@@ -41,45 +48,54 @@ const (
 	OptDocumentDefault = "/docs/api"
 )
 
-type Command struct {
-	Command managers.Command
-	Err     error
-	Name    string
-}
+type (
+	// Command
+	// for application documents.
+	Command struct {
+		Command managers.Command
+		Err     error
+		Name    string
+		Params  *Params
+	}
+
+	// Params
+	// bound with command options.
+	Params struct {
+		Adapter    string `console:"adapter,a" desc:"Specify document formatter" default:"markdown" enum:"postman,markdown"`
+		Base       string `console:"base,b" desc:"Specify your working base path" default:"./"`
+		Controller string `console:"controller,c" desc:"Specify your controller path" default:"/app/controllers"`
+		Document   string `console:"document,d" desc:"Built documents storage location" default:"/docs/api"`
+	}
+)
 
 // Handle
 // callable registered on command manager interface.
-func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
-	var (
-		s1, s2, s3, s4 string
-	)
-
-	// Read
-	// options value.
-	if s1, err = o.Command.GetOption(OptAdapter).ToString(); err != nil {
-		return
-	}
-	if s2, err = o.Command.GetOption(OptBase).ToString(); err != nil {
-		return
-	}
-	if s3, err = o.Command.GetOption(OptController).ToString(); err != nil {
-		return
-	}
-	if s4, err = o.Command.GetOption(OptDocument).ToString(); err != nil {
+func (o *Command) Handle(ctx context.Context, _ managers.Manager, a managers.Arguments) (err error) {
+	p := &Params{}
+
+	// Read options
+	// of invocation.
+	if err = a.Populate(p); err != nil {
 		return
 	}
 
 	// Use
 	// option value.
-	conf.Path.SetBasePath(s2)
-	conf.Path.SetControllerPath(s3)
-	conf.Path.SetDocumentPath(s4)
+	conf.Path.SetBasePath(p.Base)
+	conf.Path.SetControllerPath(p.Controller)
+	conf.Path.SetDocumentPath(p.Document)
 	conf.Config.Load()
 
 	// Scan
 	// controller files.
 	scanners.Scanner.Scan()
 
+	// Stop
+	// if context cancelled.
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// Reflect.
 	ref := reflectors.New(base.Mapper)
 	ref.Configure()
@@ -88,13 +104,19 @@ func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
 		return
 	}
 
-	switch s1 {
+	// Stop
+	// if context cancelled.
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	switch p.Adapter {
 	case "postman":
 		postman.New(base.Mapper).Run()
 	case "markdown":
 		markdown.New(base.Mapper).Run()
 	default:
-		err = fmt.Errorf("unknown adapter")
+		err = managers.NewValidationError("unknown adapter: %s", p.Adapter)
 	}
 
 	if err == nil {
@@ -110,18 +132,12 @@ func (o *Command) Handle(_ managers.Manager, _ managers.Arguments) (err error) {
 
 func (o *Command) InitField() *Command {
 	o.Command = managers.NewCommand(o.Name)
-	o.Command.SetDescription(CmdDesc).SetHandler(o.Handle)
+	o.Command.SetDescription(CmdDesc).SetContextHandler(o.Handle)
 	return o
 }
 
 func (o *Command) InitOption() *Command {
-	o.Err = o.Command.AddOption(
-		managers.NewOption(OptAdapter).SetShortName(OptAdapterByte).SetDescription(OptAdapterDesc).SetDefault(OptAdapterDefault),
-		managers.NewOption(OptBase).SetShortName(OptBaseByte).SetDescription(OptBaseDesc).SetDefault(OptBaseDefault),
-		managers.NewOption(OptController).SetShortName(OptControllerByte).SetDescription(OptControllerDesc).SetDefault(OptControllerDefault),
-		managers.NewOption(OptDocument).SetShortName(OptDocumentByte).SetDescription(OptDocumentDesc).SetDefault(OptDocumentDefault),
-	)
-
+	o.Err = o.Command.BindStruct(o.Params)
 	return o
 }
 
@@ -134,7 +150,7 @@ func (o *Command) InitOption() *Command {
 //     --controller=/app/controllers \
 //     --document=/docs/api
 func New() (managers.Command, error) {
-	o := (&Command{Name: CmdName}).
+	o := (&Command{Name: CmdName, Params: &Params{}}).
 		InitField().
 		InitOption()
 
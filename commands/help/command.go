@@ -5,19 +5,24 @@
 // terminal.
 //
 // # Source code mode
+//
 //   go run main.go
 //   go run main.go help
 //   go run main.go help docs
+//   go run main.go help consul kv
 //
 // # Built file mode
+//
 //   ./demo
 //   ./demo help
 //   ./demo help docs
+//   ./demo help consul kv download
 package help
 
 import (
 	"fmt"
 	"github.com/fuyibing/console/v3/managers"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -26,39 +31,72 @@ import (
 const (
 	commandName  = "help"
 	commandWidth = 100
+
+	positionalCommand     = "command"
+	positionalCommandDesc = "Command path, such as: consul kv download"
 )
 
 type Command struct {
 	Command managers.Command
 	Err     error
 	Name    string
+	Writer  io.Writer
 }
 
 // Handle
 // callable registered on command manager interface.
 func (o *Command) Handle(m managers.Manager, a managers.Arguments) error {
+	// Render with copy
+	// print to output stream of invocation, writer of command or
+	// os.Stdout used if not specified.
+	r := &Command{Command: o.Command, Name: o.Name, Writer: o.Writer}
+	if x := a.GetIO(); x != nil && x.Out != nil {
+		r.Writer = x.Out
+	}
+	if r.Writer == nil {
+		r.Writer = os.Stdout
+	}
+
 	// Handle command.
 	if key := a.GetHelpSelector(); key != "" {
 		if c := m.GetCommand(key); c != nil {
-			return o.HandleCommand(a, c)
+			return r.HandleCommand(m, a, c)
 		}
 
 		// Return error if command not recognize.
-		return fmt.Errorf("command not recognized: %s", key)
+		return managers.NewUsageError("command not recognized: %s", key)
 	}
 
 	// Handle manager.
-	return o.HandleManager(m, a)
+	return r.HandleManager(m, a)
 }
 
 // HandleCommand
 // generate command information and print.
-func (o *Command) HandleCommand(a managers.Arguments, c managers.Command) error {
+func (o *Command) HandleCommand(m managers.Manager, a managers.Arguments, c managers.Command) error {
 	o.RenderVersion()
-	o.RenderUsage(a.GetScript(), c.GetName())
+
+	// Command group
+	// render usage with children.
+	if c.IsGroup() {
+		o.RenderUsage(a.GetScript(), fmt.Sprintf("%s COMMAND", c.GetPath()))
+	} else {
+		o.RenderUsage(a.GetScript(), c.GetPath(), c.GetPositionals()...)
+	}
+
 	o.RenderDescription(c.GetDescription())
+	o.RenderAliases(c)
 
+	o.RenderPositional(c)
 	o.RenderOption(c)
+	o.RenderGlobalOption(c, m.GetInheritedOptions(c))
+	o.RenderCommands(c.GetCommands())
+
+	// Guide for
+	// command group.
+	if c.IsGroup() {
+		o.RenderGuider(a.GetScript(), c.GetPath())
+	}
 	return nil
 }
 
@@ -70,18 +108,30 @@ func (o *Command) HandleManager(m managers.Manager, a managers.Arguments) error
 	o.RenderDescription(m.GetDescription())
 
 	o.RenderOption(o.Command)
-	o.RenderCommands(m)
-	o.RenderGuider(a.GetScript())
+	o.RenderGlobalOption(nil, m.GetInheritedOptions(nil))
+	o.RenderCommands(m.GetCommands())
+	o.RenderGuider(a.GetScript(), "")
 	return nil
 }
 
+// RenderAliases
+// print command aliases.
+//
+//   Aliases: dl, down
+func (o *Command) RenderAliases(c managers.Command) {
+	if as := c.GetAliases(); len(as) > 0 {
+		o.println("")
+		o.println("Aliases: %s", strings.Join(as, ", "))
+	}
+}
+
 // RenderCommands
-// print manager command list.
+// print command list of manager or command group.
 //
 //   Commands:
-//     docs         Build application document files
-//     kv:upload    Collect local config files and upload to consul
-func (o *Command) RenderCommands(m managers.Manager) {
+//     consul    Consul kv and service management
+//     docs      Build application document files
+func (o *Command) RenderCommands(cs map[string]managers.Command) {
 	var (
 		c            managers.Command
 		index, width = 0, 0
@@ -89,7 +139,7 @@ func (o *Command) RenderCommands(m managers.Manager) {
 	)
 
 	// Range commands.
-	for _, c = range m.GetCommands() {
+	for _, c = range cs {
 		if c.GetHidden() {
 			continue
 		}
@@ -114,7 +164,7 @@ func (o *Command) RenderCommands(m managers.Manager) {
 
 	// Range commands.
 	for _, key := range keys {
-		if c = m.GetCommand(key); c == nil {
+		if c = cs[key]; c == nil {
 			continue
 		}
 
@@ -156,50 +206,149 @@ func (o *Command) RenderDescription(str string) {
 }
 
 // RenderGuider
-// print guide information.
-func (o *Command) RenderGuider(script string) {
+// print guide information, path is the command group path
+// or empty string for manager.
+func (o *Command) RenderGuider(script, path string) {
+	if path != "" {
+		path += " "
+	}
+
 	o.println("")
-	o.println("Run '%s help COMMAND' for more information on a command", script)
+	o.println("Run '%s help %sCOMMAND' for more information on a command", script, path)
 	o.println("")
 	o.println("To get more help with console, check out our guides at https://github.com/fuyibing/console/tree/v3")
 }
 
+// RenderGlobalOption
+// print options inherited from command groups and manager, options
+// of constraint groups are printed in sections.
+//
+//   Global Options:
+//     -o, --output[=string]    Output format (accept: table, json, yaml, csv) (default: table)
+func (o *Command) RenderGlobalOption(c managers.Command, opts []managers.Option) {
+	var (
+		groups  = make([]managers.OptionGroup, 0)
+		grouped = make(map[string]bool)
+		keys    = make([]string, 0)
+		list    = make(map[string]managers.Option)
+		width   = 0
+	)
+
+	for _, opt := range opts {
+		list[opt.GetName()] = opt
+
+		// Set maximum width of label.
+		if n := len(opt.GetLabel()); width < n {
+			width = n
+		}
+	}
+
+	// Constraint groups
+	// with inherited options only.
+	for x := c; x != nil; x = x.GetParent() {
+		for _, g := range x.GetOptionGroups() {
+			inherited := true
+			for _, name := range g.GetNames() {
+				if list[name] == nil {
+					inherited = false
+				}
+			}
+			if inherited {
+				groups = append(groups, g)
+				for _, name := range g.GetNames() {
+					grouped[name] = true
+				}
+			}
+		}
+	}
+
+	// Sort
+	// by option name.
+	for k := range list {
+		if !grouped[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if i == 0 {
+			o.println("")
+			o.println("Global Options:")
+		}
+		o.renderOptionLabel(width, o.globalLabel(c, list[key]), list[key])
+	}
+
+	// Range
+	// constraint groups.
+	for _, g := range groups {
+		o.println("")
+		o.println("%s:", g.GetTitle())
+
+		for _, key := range g.GetNames() {
+			o.renderOptionLabel(width, o.globalLabel(c, list[key]), list[key])
+		}
+	}
+}
+
 // RenderOption
-// print option information.
+// print option information, options of constraint groups are
+// printed in sections after ungrouped options.
 //
 //   Options:
 //     -b, --base=string      Specify working base path
 //         --config=string    Specify config path
+//
+//   Mutually exclusive options:
+//         --token=string         Consul ACL token
+//         --token-file=string    File contains consul ACL token
 func (o *Command) RenderOption(c managers.Command) {
 	var (
+		groups       = make([]managers.OptionGroup, 0)
+		grouped      = make(map[string]bool)
 		index, width = 0, 0
 		keys         = make([]string, 0)
 		opt          managers.Option
 		opts         = make(map[string]managers.Option)
 	)
 
+	// Options
+	// of constraint groups, groups of persistent options are
+	// printed with global options.
+	for _, g := range c.GetOptionGroups() {
+		declared := true
+		for _, name := range g.GetNames() {
+			if c.GetOptions()[name] == nil {
+				declared = false
+			}
+		}
+		if declared {
+			groups = append(groups, g)
+			for _, name := range g.GetNames() {
+				grouped[name] = true
+			}
+		}
+	}
+
 	// Range
 	// command options.
 	for _, opt = range c.GetOptions() {
-		keys = append(keys, opt.GetName())
 		opts[opt.GetName()] = opt
 
 		// Set maximum width of label.
 		if n := len(opt.GetLabel()); width < n {
 			width = n
 		}
+
+		if !grouped[opt.GetName()] {
+			keys = append(keys, opt.GetName())
+		}
 	}
 
 	// Sort
 	// by option name.
 	sort.Strings(keys)
 
-	// Make formatters.
-	var (
-		format = fmt.Sprintf("  %%-%ds    %%s", width)
-		holder = fmt.Sprintf("  %s    %%s", strings.Repeat(" ", width))
-	)
-
 	// Range
 	// option names.
 	for _, key := range keys {
@@ -214,23 +363,70 @@ func (o *Command) RenderOption(c managers.Command) {
 			o.println("Options:")
 		}
 
-		// Print options.
-		if cs := o.SplitWords(width, opt.GetDescription()); len(cs) > 0 {
-			// Multi-rows description on option.
+		o.renderOption(width, opt)
+	}
+
+	// Range
+	// constraint groups in declared order.
+	for _, g := range groups {
+		o.println("")
+		o.println("%s:", g.GetTitle())
+
+		for _, key := range g.GetNames() {
+			if opt = opts[key]; opt != nil {
+				o.renderOption(width, opt)
+			}
+		}
+	}
+}
+
+// RenderPositional
+// print positional argument information.
+//
+//   Arguments:
+//     <key>       Consul key name
+//     [path]      Config file storage location
+func (o *Command) RenderPositional(c managers.Command) {
+	var (
+		ps    = c.GetPositionals()
+		width = 0
+	)
+
+	// Return
+	// if no positional argument.
+	if len(ps) == 0 {
+		return
+	}
+
+	// Set maximum width of label.
+	for _, p := range ps {
+		if n := len(p.GetLabel()); width < n {
+			width = n
+		}
+	}
+
+	// Make formatters.
+	var (
+		format = fmt.Sprintf("  %%-%ds    %%s", width)
+		holder = fmt.Sprintf("  %s    %%s", strings.Repeat(" ", width))
+	)
+
+	o.println("")
+	o.println("Arguments:")
+
+	// Range
+	// positional arguments in order.
+	for _, p := range ps {
+		if cs := o.SplitWords(width, p.GetDescription()); len(cs) > 0 {
 			for i, s := range cs {
 				if i == 0 {
-					// First row
-					// of multi-rows.
-					o.println(format, opt.GetLabel(), s)
+					o.println(format, p.GetLabel(), s)
 				} else {
-					// Not first row
-					// of multi-rows.
 					o.println(holder, s)
 				}
 			}
 		} else {
-			// No description.
-			o.println(format, opt.GetLabel(), "")
+			o.println(format, p.GetLabel(), "")
 		}
 	}
 }
@@ -240,8 +436,13 @@ func (o *Command) RenderOption(c managers.Command) {
 //
 //   Usage: ./app COMMAND [OPTION]
 //   Usage: ./app help COMMAND
-func (o *Command) RenderUsage(script, name string) {
-	o.println("Usage: %s %s [OPTIONS]", script, name)
+//   Usage: ./app consul kv download [OPTIONS] <key> [path]
+func (o *Command) RenderUsage(script, name string, ps ...managers.Positional) {
+	ls := []string{script, name, "[OPTIONS]"}
+	for _, p := range ps {
+		ls = append(ls, p.GetLabel())
+	}
+	o.println("Usage: %s", strings.Join(ls, " "))
 }
 
 // RenderVersion
@@ -302,6 +503,15 @@ func (o *Command) SplitWords(w int, str string) []string {
 // Access and constructor methods
 // /////////////////////////////////////////////////////////////
 
+// Label
+// of inherited option, short name is hidden if declared by command.
+func (o *Command) globalLabel(c managers.Command, opt managers.Option) string {
+	if s := opt.GetShortName(); s != "" && c != nil && c.GetOption(s) != nil {
+		return "    " + strings.TrimPrefix(opt.GetLabel(), fmt.Sprintf("-%s, ", s))
+	}
+	return opt.GetLabel()
+}
+
 func (o *Command) initField() *Command {
 	o.Command = managers.NewCommand(o.Name)
 	o.Command.SetHidden(true).SetHandler(o.Handle)
@@ -309,18 +519,55 @@ func (o *Command) initField() *Command {
 }
 
 func (o *Command) initOption() *Command {
-	o.Err = o.Command.AddOption()
+	if o.Err = o.Command.AddOption(); o.Err == nil {
+		o.Err = o.Command.AddPositional(
+			managers.NewPositional(positionalCommand).SetDescription(positionalCommandDesc).SetVariadic(true),
+		)
+	}
 	return o
 }
 
+// Print option
+// label and multi-rows description.
+func (o *Command) renderOption(width int, opt managers.Option) {
+	o.renderOptionLabel(width, opt.GetLabel(), opt)
+}
+
+// Print option
+// with specified label.
+func (o *Command) renderOptionLabel(width int, label string, opt managers.Option) {
+	var (
+		format = fmt.Sprintf("  %%-%ds    %%s", width)
+		holder = fmt.Sprintf("  %s    %%s", strings.Repeat(" ", width))
+	)
+
+	if cs := o.SplitWords(width, opt.GetDescription()); len(cs) > 0 {
+		// Multi-rows description on option.
+		for i, s := range cs {
+			if i == 0 {
+				// First row
+				// of multi-rows.
+				o.println(format, label, s)
+			} else {
+				// Not first row
+				// of multi-rows.
+				o.println(holder, s)
+			}
+		}
+	} else {
+		// No description.
+		o.println(format, label, "")
+	}
+}
+
 func (o *Command) println(format string, args ...interface{}) {
-	_, _ = fmt.Fprintf(os.Stdout, "%s\n", fmt.Sprintf(format, args...))
+	_, _ = fmt.Fprintf(o.Writer, "%s\n", fmt.Sprintf(format, args...))
 }
 
 // New
 // function create and return instance.
 func New() (managers.Command, error) {
-	o := (&Command{Name: commandName}).
+	o := (&Command{Name: commandName, Writer: os.Stdout}).
 		initField().
 		initOption()
 
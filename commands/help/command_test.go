@@ -0,0 +1,29 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package help
+
+import (
+	"bytes"
+	"github.com/fuyibing/console/v3/managers"
+	"strings"
+	"testing"
+)
+
+func TestHandleWithoutWriter(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		m   = managers.NewManager()
+		o   = (&Command{Name: commandName, Writer: &buf}).initField().initOption()
+	)
+
+	for _, x := range []*managers.IO{nil, {}, {Err: &bytes.Buffer{}}} {
+		buf.Reset()
+		if err := o.Handle(m, managers.NewArguments().SetIO(x)); err != nil {
+			t.Fatalf("handle failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), "Usage:") {
+			t.Errorf("io: %+v, output: %q", x, buf.String())
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+// Package shell
+// interactive prompt to run commands of manager without
+// re-invoking the binary.
+//
+//   ./demo shell
+//   demo> set --addr=127.0.0.1:8500
+//   demo> consul kv download --name=app/myapp
+//   demo> exit
+package shell
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"github.com/peterh/liner"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CmdDesc = "Start interactive shell to run commands"
+	CmdName = "shell"
+)
+
+type (
+	// Command
+	// for interactive shell.
+	Command struct {
+		Command managers.Command
+		Err     error
+		Name    string
+		Params  *Params
+	}
+
+	// Params
+	// bound with command options.
+	Params struct {
+		History string `console:"history" desc:"History file, default is .<program>_history in home directory"`
+		Prompt  string `console:"prompt" desc:"Prompt text, default is program name"`
+	}
+
+	// Reader
+	// read line with prompt.
+	reader interface {
+		Close() error
+		Prompt(prompt string) (string, error)
+	}
+
+	// Reader
+	// for not terminal input.
+	scanReader struct {
+		Out     io.Writer
+		Scanner *bufio.Scanner
+	}
+
+	// Reader
+	// for terminal with line editing, history and completion.
+	termReader struct {
+		History string
+		State   *liner.State
+	}
+)
+
+// Handle
+// read lines and run until exit or input end.
+func (o *Command) Handle(ctx context.Context, m managers.Manager, a managers.Arguments) (err error) {
+	var (
+		line    string
+		p       = &Params{}
+		program = filepath.Base(os.Args[0])
+		prompt  string
+		r       reader
+		s       = NewSession(m, a.GetIO())
+	)
+
+	// Read options
+	// of invocation.
+	if err = a.Populate(p); err != nil {
+		return
+	}
+
+	// Prompt text.
+	if prompt = p.Prompt; prompt == "" {
+		prompt = program
+	}
+	prompt += "> "
+
+	// Open reader
+	// and close when end.
+	r = o.reader(a.GetIO(), s, p.History, program)
+	defer func() {
+		if ce := r.Close(); err == nil {
+			err = ce
+		}
+	}()
+
+	_, _ = fmt.Fprintln(a.GetIO().Out, Welcome)
+
+	// Read lines.
+	for {
+		// Stop
+		// if context cancelled.
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		// Read line.
+		if line, err = r.Prompt(prompt); err != nil {
+			// Clear line
+			// if ctrl+c pressed.
+			if err == liner.ErrPromptAborted {
+				continue
+			}
+
+			// Exit
+			// if ctrl+d pressed or input end.
+			if err == io.EOF {
+				_, _ = fmt.Fprintln(a.GetIO().Out)
+				err = nil
+			}
+			return
+		}
+
+		// Run line.
+		if err = s.Run(ctx, a.GetScript(), line); err != nil {
+			if errors.Is(err, ErrExit) {
+				err = nil
+				return
+			}
+			_, _ = fmt.Fprintln(a.GetIO().Err, err.Error())
+		}
+	}
+}
+
+// InitField
+// initialize command fields.
+func (o *Command) InitField() *Command {
+	o.Command = managers.NewCommand(o.Name)
+	o.Command.SetDescription(CmdDesc).SetContextHandler(o.Handle)
+	return o
+}
+
+// InitOption
+// initialize command option.
+func (o *Command) InitOption() *Command {
+	o.Err = o.Command.BindStruct(o.Params)
+	return o
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor methods
+// /////////////////////////////////////////////////////////////
+
+// Create reader, line editing
+// enabled if input is terminal.
+func (o *Command) reader(x *managers.IO, s *Session, history, program string) reader {
+	if f, ok := x.In.(*os.File); !ok || f != os.Stdin || !liner.TerminalSupported() {
+		return &scanReader{Out: x.Out, Scanner: bufio.NewScanner(x.In)}
+	}
+
+	r := &termReader{History: history, State: liner.NewLiner()}
+	r.State.SetCtrlCAborts(true)
+	r.State.SetCompleter(s.Complete)
+
+	// History file
+	// in home directory.
+	if r.History == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			r.History = filepath.Join(home, fmt.Sprintf(".%s_history", program))
+		}
+	}
+
+	// Load history.
+	if f, err := os.Open(r.History); err == nil {
+		_, _ = r.State.ReadHistory(f)
+		_ = f.Close()
+	}
+	return r
+}
+
+func (o *scanReader) Close() error { return nil }
+
+func (o *scanReader) Prompt(prompt string) (string, error) {
+	if _, err := io.WriteString(o.Out, prompt); err != nil {
+		return "", err
+	}
+	if o.Scanner.Scan() {
+		return o.Scanner.Text(), nil
+	}
+	if err := o.Scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+// Close
+// terminal and save history.
+func (o *termReader) Close() (err error) {
+	var f *os.File
+
+	if o.History != "" {
+		if f, err = os.Create(o.History); err == nil {
+			_, err = o.State.WriteHistory(f)
+			_ = f.Close()
+		}
+	}
+	if ce := o.State.Close(); err == nil {
+		err = ce
+	}
+	return
+}
+
+func (o *termReader) Prompt(prompt string) (line string, err error) {
+	if line, err = o.State.Prompt(prompt); err == nil && strings.TrimSpace(line) != "" {
+		o.State.AppendHistory(line)
+	}
+	return
+}
+
+// New
+// create and return instance.
+//
+//   ./demo shell
+//   ./demo shell --history=/tmp/demo_history --prompt=demo
+func New() (managers.Command, error) {
+	o := (&Command{Name: CmdName, Params: &Params{}}).
+		InitField().
+		InitOption()
+
+	return o.Command, o.Err
+}
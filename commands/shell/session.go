@@ -0,0 +1,387 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fuyibing/console/v3/commands/completion"
+	"github.com/fuyibing/console/v3/managers"
+	"sort"
+	"strings"
+)
+
+const (
+	Welcome = "Type 'help' to list commands, 'set --name=value' to set session default, 'exit' to quit."
+)
+
+var (
+	// Builtins
+	// commands of shell session.
+	Builtins = []string{"defaults", "exit", "quit", "set", "unset"}
+
+	// ErrExit
+	// returned if exit or quit typed.
+	ErrExit = errors.New("exit shell")
+)
+
+// Session
+// of shell, defaults are applied to commands which declared the
+// option and not specified on line.
+//
+//   set --addr=127.0.0.1:8500 --scheme=https
+//   unset addr
+//   defaults
+type Session struct {
+	Defaults map[string]string
+	IO       *managers.IO
+	Manager  managers.Manager
+
+	complete *completion.Complete
+}
+
+// NewSession
+// create and return shell session of manager, commands are run
+// with standard streams x.
+func NewSession(m managers.Manager, x *managers.IO) *Session {
+	return &Session{
+		Defaults: make(map[string]string),
+		IO:       x,
+		Manager:  m,
+		complete: &completion.Complete{},
+	}
+}
+
+// Complete
+// return candidates of line for tab completion.
+func (o *Session) Complete(line string) (list []string) {
+	var (
+		candidates []string
+		words      = strings.Fields(line)
+	)
+
+	// Completing
+	// a new word.
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+
+	var (
+		current = words[len(words)-1]
+		head    = line[:len(line)-len(current)]
+	)
+
+	switch {
+	case len(words) == 1:
+		for _, s := range Builtins {
+			if strings.HasPrefix(s, current) {
+				candidates = append(candidates, s)
+			}
+		}
+		candidates = append(candidates, o.complete.Candidates(o.Manager, words)...)
+	case words[0] == "set" || words[0] == "unset":
+		candidates = o.options(current)
+	default:
+		candidates = o.complete.Candidates(o.Manager, words)
+	}
+
+	sort.Strings(candidates)
+	for _, s := range candidates {
+		list = append(list, head+s)
+	}
+	return
+}
+
+// Run
+// built-in or manager command of line.
+func (o *Session) Run(ctx context.Context, script, line string) (err error) {
+	var words []string
+
+	if words, err = Split(line); err != nil || len(words) == 0 {
+		return
+	}
+
+	switch words[0] {
+	case "exit", "quit":
+		return ErrExit
+	case "defaults":
+		return o.print()
+	case "set":
+		return o.set(words[1:])
+	case "unset":
+		o.unset(words[1:])
+		return
+	case CmdName:
+		return fmt.Errorf("shell is running")
+	}
+
+	// Run command,
+	// error message written by manager.
+	o.Manager.Execute(ctx, o.IO, append([]string{script}, o.apply(words)...)...)
+	return
+}
+
+// Split
+// line into words, quotes and backslash escapes are accepted.
+//
+//   consul kv upload --name="app/my app" -> [consul kv upload --name=app/my app]
+func Split(line string) (words []string, err error) {
+	var (
+		escape, started bool
+		quote           rune
+		word            strings.Builder
+	)
+
+	for _, c := range line {
+		switch {
+		case escape:
+			word.WriteRune(c)
+			escape = false
+		case c == '\\' && quote != '\'':
+			escape, started = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote, started = c, true
+		case c == ' ' || c == '\t':
+			if started {
+				words = append(words, word.String())
+				word.Reset()
+				started = false
+			}
+		default:
+			word.WriteRune(c)
+			started = true
+		}
+	}
+
+	// Return error
+	// if quote not closed.
+	if quote != 0 || escape {
+		return nil, fmt.Errorf("unterminated quote or escape: %s", line)
+	}
+	if started {
+		words = append(words, word.String())
+	}
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor methods
+// /////////////////////////////////////////////////////////////
+
+// Apply defaults
+// to options of selected command.
+func (o *Session) apply(words []string) []string {
+	var (
+		cmd  managers.Command
+		end  = len(words)
+		keys = make([]string, 0)
+	)
+
+	// Selected command
+	// with the longest path.
+	for i := 1; i <= len(words) && !strings.HasPrefix(words[i-1], "-"); i++ {
+		if c := o.Manager.GetCommand(strings.Join(words[:i], " ")); c != nil {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		return words
+	}
+
+	// Insert position
+	// before terminator.
+	for i, s := range words {
+		if s == managers.ArgumentsTerminator {
+			end = i
+			break
+		}
+	}
+
+	// Default options
+	// not specified on line.
+	for k := range o.Defaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := append([]string{}, words[:end]...)
+	for _, k := range keys {
+		if opt := o.option(cmd, k); opt != nil && !o.specified(opt, words[:end]) {
+			res = append(res, fmt.Sprintf("--%s=%s", k, o.Defaults[k]))
+		}
+	}
+	return append(res, words[end:]...)
+}
+
+// Option
+// declared by command or inherited.
+func (o *Session) option(c managers.Command, key string) managers.Option {
+	if opt := c.GetOption(key); opt != nil {
+		return opt
+	}
+	for _, opt := range o.Manager.GetInheritedOptions(c) {
+		if opt.GetName() == key {
+			return opt
+		}
+	}
+	return nil
+}
+
+// Option names
+// of all commands for set and unset.
+func (o *Session) options(current string) (list []string) {
+	names := make(map[string]bool)
+	o.walk(func(opt managers.Option) {
+		names["--"+opt.GetName()] = true
+	})
+
+	for s := range names {
+		if strings.HasPrefix(s, current) {
+			list = append(list, s)
+		}
+	}
+	return
+}
+
+// Print
+// session defaults.
+func (o *Session) print() (err error) {
+	keys := make([]string, 0)
+	for k := range o.Defaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err = fmt.Fprintf(o.IO.Out, "--%s=%s\n", k, o.Defaults[k]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Resolve
+// short option name to full name, return error if no option or
+// options of different full names use the short name.
+//
+//   a -> addr
+func (o *Session) resolve(key string) (string, error) {
+	if len(key) != 1 {
+		return key, nil
+	}
+
+	names := make(map[string]bool)
+	o.walk(func(opt managers.Option) {
+		if opt.GetShortName() == key {
+			names[opt.GetName()] = true
+		}
+	})
+
+	list := make([]string, 0)
+	for k := range names {
+		list = append(list, "--"+k)
+	}
+	sort.Strings(list)
+
+	switch len(list) {
+	case 0:
+		return "", fmt.Errorf("option not recognized: -%s", key)
+	case 1:
+		return strings.TrimPrefix(list[0], "--"), nil
+	}
+	return "", fmt.Errorf("option -%s is ambiguous, use full name: %s", key, strings.Join(list, ", "))
+}
+
+// Set defaults,
+// accept: --name=value, name=value, --name.
+func (o *Session) set(words []string) error {
+	if len(words) == 0 {
+		return fmt.Errorf("usage: set --name=value")
+	}
+
+	for _, s := range words {
+		var (
+			kv = strings.SplitN(strings.TrimLeft(s, "-"), "=", 2)
+			k  = kv[0]
+		)
+
+		if k == "" {
+			return fmt.Errorf("option not recognized: %s", s)
+		}
+
+		// Store
+		// with full name.
+		var err error
+		if k, err = o.resolve(k); err != nil {
+			return err
+		}
+		if len(kv) == 2 {
+			o.Defaults[k] = kv[1]
+		} else {
+			o.Defaults[k] = "true"
+		}
+	}
+	return nil
+}
+
+// Option specified
+// on line with full name or short name.
+func (o *Session) specified(opt managers.Option, words []string) bool {
+	for _, s := range words {
+		if s == "--"+opt.GetName() || strings.HasPrefix(s, "--"+opt.GetName()+"=") {
+			return true
+		}
+		if sn := opt.GetShortName(); sn != "" && !strings.HasPrefix(s, "--") && strings.HasPrefix(s, "-") && strings.Contains(strings.SplitN(s, "=", 2)[0], sn) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove defaults,
+// all defaults removed if names not specified.
+func (o *Session) unset(words []string) {
+	if len(words) == 0 {
+		o.Defaults = make(map[string]string)
+		return
+	}
+	for _, s := range words {
+		k := strings.SplitN(strings.TrimLeft(s, "-"), "=", 2)[0]
+		if name, err := o.resolve(k); err == nil {
+			k = name
+		}
+		delete(o.Defaults, k)
+	}
+}
+
+// Walk options
+// of all commands, persistent options and global options.
+func (o *Session) walk(fn func(opt managers.Option)) {
+	var walk func(cs map[string]managers.Command)
+
+	walk = func(cs map[string]managers.Command) {
+		for _, c := range cs {
+			for _, opt := range c.GetOptions() {
+				fn(opt)
+			}
+			for _, opt := range c.GetPersistentOptions() {
+				fn(opt)
+			}
+			walk(c.GetCommands())
+		}
+	}
+	walk(o.Manager.GetCommands())
+
+	// Global options.
+	for _, opt := range o.Manager.GetOptions() {
+		fn(opt)
+	}
+}
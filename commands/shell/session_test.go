@@ -0,0 +1,82 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package shell
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/fuyibing/console/v3/managers"
+	"strings"
+	"testing"
+)
+
+func TestSessionShortName(t *testing.T) {
+	type params struct {
+		Addr string `console:"addr,a" desc:"Addr"`
+		Name string `console:"name,n" desc:"Name"`
+	}
+
+	var (
+		m   = managers.NewManager()
+		out bytes.Buffer
+		x   = &managers.IO{Out: &out, Err: &bytes.Buffer{}}
+		s   = NewSession(m, x)
+	)
+
+	c := managers.NewCommand("show")
+	if err := c.BindStruct(&params{}); err != nil {
+		t.Fatalf("bind struct failed: %v", err)
+	}
+	c.SetHandler(func(m managers.Manager, a managers.Arguments) error {
+		p := &params{}
+		if err := a.Populate(p); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(a.GetIO().Out, "%s %s", p.Addr, p.Name)
+		return err
+	})
+
+	// Short name
+	// n of different full names.
+	other := managers.NewCommand("count")
+	if err := other.AddOption(managers.NewOption("number").SetShortName('n')); err != nil {
+		t.Fatalf("add option failed: %v", err)
+	}
+	for _, cmd := range []managers.Command{c, other} {
+		if err := m.AddCommand(cmd); err != nil {
+			t.Fatalf("add command failed: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	if err := s.Run(ctx, "demo", "set -a=127.0.0.1"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if v, ok := s.Defaults["addr"]; !ok || v != "127.0.0.1" || len(s.Defaults) != 1 {
+		t.Fatalf("defaults: %v", s.Defaults)
+	}
+	if err := s.Run(ctx, "demo", "show -n app"); err != nil || out.String() != "127.0.0.1 app" {
+		t.Fatalf("output: %q, error: %v", out.String(), err)
+	}
+
+	// Specified
+	// on line with short name.
+	out.Reset()
+	if err := s.Run(ctx, "demo", "show -a 10.0.0.1"); err != nil || out.String() != "10.0.0.1 " {
+		t.Fatalf("output: %q, error: %v", out.String(), err)
+	}
+
+	if err := s.Run(ctx, "demo", "set -n=app"); err == nil || !strings.Contains(err.Error(), "--name, --number") {
+		t.Errorf("ambiguous error expected, got: %v", err)
+	}
+	if err := s.Run(ctx, "demo", "set -x=1"); err == nil {
+		t.Errorf("not recognized error expected")
+	}
+
+	s.unset([]string{"-a"})
+	if len(s.Defaults) != 0 {
+		t.Errorf("defaults: %v", s.Defaults)
+	}
+}
@@ -4,8 +4,10 @@
 package main
 
 import (
+	"fmt"
 	"github.com/fuyibing/console/v3"
 	"github.com/fuyibing/console/v3/managers"
+	"os"
 )
 
 var (
@@ -20,10 +22,14 @@ func init() {
 }
 
 func main() {
-	if err == nil {
-		err = manager.RunTerminal()
-	}
+	// Exit
+	// if manager initialize failed.
 	if err != nil {
-		println(err.Error())
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(managers.ErrorCode(err))
 	}
+
+	// Run command, error is reported and
+	// process exit with code of error.
+	_ = manager.RunTerminal()
 }
@@ -7,6 +7,7 @@
 //   go run main.go
 //   go run main.go help
 //   go run main.go help docs
+//   go run main.go help consul kv
 //
 // > Build binary file named as demo
 //   go build -o demo
@@ -18,12 +19,14 @@
 package console
 
 import (
-	"github.com/fuyibing/console/v3/commands/consul/kv/download"
-	"github.com/fuyibing/console/v3/commands/consul/kv/upload"
-	"github.com/fuyibing/console/v3/commands/consul/service/deregister"
-	"github.com/fuyibing/console/v3/commands/consul/service/register"
+	"github.com/fuyibing/console/v3/commands/clidocs"
+	"github.com/fuyibing/console/v3/commands/completion"
+	"github.com/fuyibing/console/v3/commands/consul"
+	"github.com/fuyibing/console/v3/commands/consul/kv"
+	"github.com/fuyibing/console/v3/commands/consul/service"
 	"github.com/fuyibing/console/v3/commands/docs"
 	"github.com/fuyibing/console/v3/commands/help"
+	"github.com/fuyibing/console/v3/commands/shell"
 	"github.com/fuyibing/console/v3/managers"
 )
 
@@ -35,11 +38,12 @@ func Default() (mng managers.Manager, err error) {
 
 		// Built-in command definitions.
 		list = []func() (managers.Command, error){
+			clidocs.New,
+			completion.New,
+			completion.NewComplete,
 			docs.New,
-			download.New,
-			upload.New,
-			deregister.New,
-			register.New,
+			shell.New,
+			Consul,
 		}
 	)
 
@@ -58,6 +62,39 @@ func Default() (mng managers.Manager, err error) {
 				return
 			}
 		}
+
+		// Hidden aliases
+		// of commands before consul command group.
+		for alias, path := range map[string]string{
+			"kv:download":        "consul kv download",
+			"kv:upload":          "consul kv upload",
+			"service:deregister": "consul service deregister",
+			"service:register":   "consul service register",
+		} {
+			if err = mng.AddAlias(alias, path); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Consul
+// function create and return consul command group, consul config
+// options are persistent and inherited by all consul commands.
+//
+//   go run main.go consul kv download
+//   go run main.go consul service register
+func Consul() (c managers.Command, err error) {
+	if c, err = managers.NewCommandGroup("consul", "Consul kv and service management",
+		kv.New,
+		service.New,
+	); err != nil {
+		return
+	}
+
+	if err = c.BindPersistentStruct(&consul.Config{}); err == nil {
+		err = c.AddOptionGroup(consul.ConfigGroups()...)
 	}
 	return
 }
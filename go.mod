@@ -3,6 +3,7 @@ module github.com/fuyibing/console/v3
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/armon/go-metrics v0.3.10 // indirect
 	github.com/fuyibing/gdoc v0.2.7
 	github.com/google/btree v1.0.0 // indirect
@@ -12,9 +13,12 @@ require (
 	github.com/hashicorp/go-immutable-radix v1.3.0 // indirect
 	github.com/hashicorp/go-msgpack v0.5.5 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
+	github.com/hashicorp/hcl v1.0.0
 	github.com/mitchellh/mapstructure v1.4.3 // indirect
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
+	github.com/peterh/liner v1.1.0
 	github.com/stretchr/testify v1.8.0 // indirect
 	golang.org/x/net v0.4.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
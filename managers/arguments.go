@@ -4,14 +4,15 @@
 package managers
 
 import (
-	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 const (
-	ArgumentsHelp   = "help"
-	ArgumentsScript = "go run main.go"
+	ArgumentsHelp       = "help"
+	ArgumentsScript     = "go run main.go"
+	ArgumentsTerminator = "--"
 )
 
 var (
@@ -24,26 +25,53 @@ var (
 
 type (
 	// Arguments
-	// operation interface.
+	// operation interface, parsed values of an invocation live in
+	// arguments and shared command options are not changed, so the
+	// manager can run commands repeatedly and concurrently.
 	Arguments interface {
+		Bind(c Command, inherited ...Option) error
 		Get(key string) string
 		GetHelpSelector() string
+		GetIO() *IO
 		GetMapper() map[string]string
+		GetOption(key string) Option
+		GetOptions() map[string]Option
+		GetOutput() OutputManager
+		GetPositional(name string) string
+		GetPositionalSlice(name string) []string
+		GetPositionals() []string
 		GetScript() string
 		GetSelector() string
+		GetValues(key string) []string
 		Has(key string) bool
+		HasPositional(name string) bool
 		Parse(ss ...string) error
+		Populate(v interface{}) error
+		SetIO(x *IO) Arguments
 	}
 
 	arguments struct {
+		Command                        Command
+		IO                             *IO
+		Inherited                      []Option
 		Mapper                         map[string]string
+		Options                        map[string]Option
+		Output                         OutputManager
+		PositionalMapper               map[string][]string
+		Positionals, Tokens            []string
 		Selector, HelpSelector, Script string
+		Values                         map[string][]string
 	}
 )
 
 func NewArguments() Arguments {
 	return &arguments{
-		Mapper: make(map[string]string),
+		Mapper:           make(map[string]string),
+		Options:          make(map[string]Option),
+		PositionalMapper: make(map[string][]string),
+		Positionals:      make([]string, 0),
+		Tokens:           make([]string, 0),
+		Values:           make(map[string][]string),
 	}
 }
 
@@ -51,18 +79,223 @@ func NewArguments() Arguments {
 // Interface methods
 // /////////////////////////////////////////////////////////////
 
-func (o *arguments) Get(key string) string        { return o.get(key) }
-func (o *arguments) GetHelpSelector() string      { return o.HelpSelector }
-func (o *arguments) GetMapper() map[string]string { return o.Mapper }
-func (o *arguments) GetScript() string            { return o.Script }
-func (o *arguments) GetSelector() string          { return o.Selector }
-func (o *arguments) Has(key string) bool          { return o.has(key) }
-func (o *arguments) Parse(ss ...string) error     { return o.parse(ss) }
+func (o *arguments) Bind(c Command, inherited ...Option) error { return o.bind(c, inherited) }
+func (o *arguments) Get(key string) string                     { return o.get(key) }
+func (o *arguments) GetHelpSelector() string                   { return o.HelpSelector }
+func (o *arguments) GetIO() *IO                                { return o.IO }
+func (o *arguments) GetMapper() map[string]string              { return o.Mapper }
+func (o *arguments) GetOption(key string) Option               { return o.getOption(key) }
+func (o *arguments) GetOptions() map[string]Option             { return o.Options }
+func (o *arguments) GetOutput() OutputManager                  { return o.getOutput() }
+func (o *arguments) GetPositional(name string) string          { return o.getPositional(name) }
+func (o *arguments) GetPositionalSlice(name string) []string   { return o.PositionalMapper[name] }
+func (o *arguments) GetPositionals() []string                  { return o.Positionals }
+func (o *arguments) GetScript() string                         { return o.Script }
+func (o *arguments) GetSelector() string                       { return o.Selector }
+func (o *arguments) GetValues(key string) []string             { return o.Values[key] }
+func (o *arguments) Has(key string) bool                       { return o.has(key) }
+func (o *arguments) HasPositional(name string) bool            { return len(o.PositionalMapper[name]) > 0 }
+func (o *arguments) Parse(ss ...string) error                  { return o.parse(ss) }
+func (o *arguments) Populate(v interface{}) error              { return o.populate(v) }
+func (o *arguments) SetIO(x *IO) Arguments                     { o.IO = x; return o }
 
 // /////////////////////////////////////////////////////////////
 // Access and constructor
 // /////////////////////////////////////////////////////////////
 
+// Bind
+// command options and positional arguments with parsed tokens,
+// options of command and inherited options, such as persistent
+// options of command group and global options of manager, are
+// copied as values of this invocation.
+//
+// Leading words of command path are skipped, an option accepts at
+// most one word as value, and others are positional arguments.
+//
+//   go run main.go consul kv download app/myapp --override ./config
+//   go run main.go consul kv download --addr=127.0.0.1 -- -app/myapp
+func (o *arguments) bind(c Command, inherited []Option) error {
+	var (
+		keys       = make([]string, 0)
+		n          = len(strings.Fields(c.GetPath()))
+		terminated bool
+		values     = make([]string, 0)
+		words      = make([]string, 0)
+	)
+
+	// Reset
+	// generic parsed results.
+	o.Command = c
+	o.Inherited = inherited
+	o.Mapper = make(map[string]string)
+	o.Options = make(map[string]Option)
+	o.PositionalMapper = make(map[string][]string)
+	o.Selector = c.GetPath()
+	o.Values = make(map[string][]string)
+
+	// Copy options
+	// without values, command options shadow inherited.
+	for _, opt := range inherited {
+		o.Options[opt.GetName()] = opt.Clone()
+	}
+	for k, opt := range c.GetOptions() {
+		o.Options[k] = opt.Clone()
+	}
+
+	// Skip leading
+	// words of command path.
+	for i, s := range o.Tokens {
+		if i >= n || ArgumentsRegexOption.MatchString(s) {
+			n = i
+			break
+		}
+	}
+
+	// Range tokens
+	// after command path.
+	for i, s := range o.Tokens {
+		if i < n {
+			continue
+		}
+
+		// Positional words
+		// after terminator.
+		if terminated {
+			words = append(words, s)
+			continue
+		}
+
+		// Find
+		// option value or positional word.
+		if !ArgumentsRegexOption.MatchString(s) {
+			if len(keys) > 0 && len(values) == 0 && o.accept(keys[len(keys)-1], s) {
+				values = append(values, s)
+			} else {
+				words = append(words, s)
+			}
+			continue
+		}
+
+		// Collect tmp
+		// to mapper and reset.
+		if len(keys) > 0 {
+			if err := o.setter(keys, values); err != nil {
+				return err
+			}
+
+			keys = make([]string, 0)
+			values = make([]string, 0)
+		}
+
+		// Stop option
+		// parsing on terminator.
+		if s == ArgumentsTerminator {
+			terminated = true
+			continue
+		}
+
+		// Find
+		// key/value pairs.
+		if m := ArgumentsRegexOptionPairs.FindStringSubmatch(s); len(m) == 3 {
+			if err := o.setter([]string{m[1]}, []string{m[2]}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Find
+		// argument option.
+		if m := ArgumentsRegexOptionName.FindStringSubmatch(s); len(m) == 3 {
+			if m[1] == "-" {
+				for _, x := range m[2] {
+					keys = append(keys, string(x))
+				}
+			} else {
+				keys = []string{m[2]}
+			}
+		}
+	}
+
+	// Collect tmp
+	// to mapper if not empty.
+	if len(keys) > 0 {
+		if err := o.setter(keys, values); err != nil {
+			return err
+		}
+	}
+
+	// Return error
+	// if option specified twice but not repeatable.
+	for k, vs := range o.Values {
+		if len(vs) > 1 {
+			if opt := o.lookup(k); opt == nil || !opt.IsRepeatable() {
+				return NewUsageError("option can not specify twice: %s", k)
+			}
+		}
+	}
+
+	o.Positionals = words
+	return o.bindPositional(c, words)
+}
+
+// Assign words
+// to positional arguments of command in order.
+func (o *arguments) bindPositional(c Command, words []string) error {
+	for _, p := range c.GetPositionals() {
+		var vs []string
+
+		// Variadic argument
+		// receive all remaining words.
+		if p.IsVariadic() {
+			vs, words = words, nil
+		} else if len(words) > 0 {
+			vs, words = words[:1], words[1:]
+		}
+
+		// Return error
+		// if validate failed.
+		if err := p.Validate(vs); err != nil {
+			return err
+		}
+
+		if len(vs) > 0 {
+			o.PositionalMapper[p.GetName()] = vs
+		}
+	}
+
+	// Return error
+	// if too many words.
+	if len(words) > 0 {
+		return NewUsageError("argument not recognized: %s", words[0])
+	}
+	return nil
+}
+
+// Option accept word
+// as value or not.
+func (o *arguments) accept(key, word string) bool {
+	if opt := o.lookup(key); opt != nil {
+		return acceptValue(opt, word)
+	}
+
+	// Abbreviated name
+	// of boolean or count option accept no value, such as --over
+	// for --override.
+	if len(key) > 1 {
+		names := make([]string, 0)
+		for k := range o.Options {
+			names = append(names, k)
+		}
+		if k, ok := Abbreviate(key, names); ok {
+			switch o.Options[k].GetValueType() {
+			case ValueTypeBoolean, ValueTypeCount, ValueTypeNull:
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (o *arguments) get(key string) string {
 	if s, ok := o.Mapper[key]; ok {
 		return s
@@ -70,6 +303,35 @@ func (o *arguments) get(key string) string {
 	return ""
 }
 
+// Option value
+// of invocation by full name or short name.
+func (o *arguments) getOption(key string) Option {
+	if opt := o.lookup(key); opt != nil {
+		return o.Options[opt.GetName()]
+	}
+	return nil
+}
+
+// Output manager
+// of invocation, writes to output stream.
+func (o *arguments) getOutput() OutputManager {
+	if o.Output == nil {
+		if o.IO != nil {
+			o.Output = NewOutput(o.IO.Out)
+		} else {
+			o.Output = NewOutput(nil)
+		}
+	}
+	return o.Output
+}
+
+func (o *arguments) getPositional(name string) string {
+	if vs := o.PositionalMapper[name]; len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
 func (o *arguments) has(key string) bool {
 	if _, ok := o.Mapper[key]; ok {
 		return true
@@ -77,28 +339,54 @@ func (o *arguments) has(key string) bool {
 	return false
 }
 
+// Option definition
+// of command or inherited options by full name or short name.
+func (o *arguments) lookup(key string) Option {
+	if o.Command != nil {
+		if opt := o.Command.GetOption(key); opt != nil {
+			return opt
+		}
+	}
+	for _, opt := range o.Inherited {
+		if opt.GetName() == key || opt.GetShortName() == key {
+			return opt
+		}
+	}
+	return nil
+}
+
 func (o *arguments) parse(ss []string) error {
 	var (
-		keys   = make([]string, 0)
-		values = make([]string, 0)
+		keys       = make([]string, 0)
+		selectors  = make([]string, 0)
+		terminated bool
+		values     = make([]string, 0)
 	)
 
 	// Range args.
 	for i, s := range ss {
+		if i > 0 {
+			o.Tokens = append(o.Tokens, s)
+		}
+
+		// Ignore words
+		// after terminator.
+		if terminated {
+			continue
+		}
+
 		// Find
 		// arguments value.
 		if !ArgumentsRegexOption.MatchString(s) {
-			switch i {
-			case 0:
+			switch {
+			case i == 0:
 				o.parseScript(s)
-			case 1:
-				o.Selector = s
+			case len(selectors) == i-1:
+				// Leading words are
+				// command path, such as: consul kv download.
+				selectors = append(selectors, s)
 			default:
-				if i == 2 && o.Selector == ArgumentsHelp {
-					o.HelpSelector = s
-				} else {
-					values = append(values, s)
-				}
+				values = append(values, s)
 			}
 			continue
 		}
@@ -117,6 +405,13 @@ func (o *arguments) parse(ss []string) error {
 			values = make([]string, 0)
 		}
 
+		// Stop option
+		// parsing on terminator.
+		if s == ArgumentsTerminator {
+			terminated = true
+			continue
+		}
+
 		// Find
 		// key/value pairs.
 		if m := ArgumentsRegexOptionPairs.FindStringSubmatch(s); len(m) == 3 {
@@ -149,6 +444,17 @@ func (o *arguments) parse(ss []string) error {
 		}
 	}
 
+	// Build selector
+	// and help selector with command path.
+	if len(selectors) > 0 {
+		if selectors[0] == ArgumentsHelp {
+			o.Selector = ArgumentsHelp
+			o.HelpSelector = strings.Join(selectors[1:], " ")
+		} else {
+			o.Selector = strings.Join(selectors, " ")
+		}
+	}
+
 	return nil
 }
 
@@ -160,6 +466,16 @@ func (o *arguments) parseScript(s string) {
 	}
 }
 
+// Populate
+// struct fields with option values of invocation.
+func (o *arguments) populate(v interface{}) error {
+	b, err := NewBinder(v)
+	if err != nil {
+		return err
+	}
+	return b.Populate(o)
+}
+
 func (o *arguments) setter(keys, values []string) error {
 	var (
 		n  = len(keys) - 1
@@ -167,21 +483,35 @@ func (o *arguments) setter(keys, values []string) error {
 	)
 
 	// Range key
-	// and assign value on last key.
+	// and assign value on last key, each occurrence is kept
+	// in values for repeatable options.
 	for i, key := range keys {
-		if _, ok := o.Mapper[key]; ok {
-			return fmt.Errorf("option can not specify twice: %s", key)
-		}
-
-		// Set mapper.
-		if i == n {
-			// Assign on last.
-			o.Mapper[key] = vs
-		} else {
+		if i != n {
 			// Assign empty on not last.
 			o.Mapper[key] = ""
+		} else {
+			// Assign on last.
+			o.Mapper[key] = vs
+		}
+
+		if o.Values == nil {
+			o.Values = make(map[string][]string)
 		}
+		o.Values[key] = append(o.Values[key], o.Mapper[key])
 	}
 
 	return nil
 }
+
+// Option accept word
+// as value or not, boolean option accept boolean word only.
+func acceptValue(opt Option, word string) bool {
+	switch opt.GetValueType() {
+	case ValueTypeCount, ValueTypeNull:
+		return false
+	case ValueTypeBoolean:
+		_, err := strconv.ParseBool(word)
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,494 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-19
+
+package managers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BindingTagConfig      = "config"
+	BindingTagCount       = "count"
+	BindingTagDefault     = "default"
+	BindingTagDescription = "desc"
+	BindingTagEnum        = "enum"
+	BindingTagEnv         = "env"
+	BindingTagLength      = "length"
+	BindingTagMax         = "max"
+	BindingTagMin         = "min"
+	BindingTagName        = "console"
+	BindingTagRegex       = "regex"
+	BindingTagRequired    = "required"
+	BindingTagType        = "type"
+)
+
+var (
+	// BindingTypes
+	// value type of field type, checked before field kind.
+	BindingTypes = map[reflect.Type]ValueType{
+		reflect.TypeOf(&net.IPNet{}):     ValueTypeCIDR,
+		reflect.TypeOf(&url.URL{}):       ValueTypeURL,
+		reflect.TypeOf(net.IP{}):         ValueTypeIP,
+		reflect.TypeOf(time.Duration(0)): ValueTypeDuration,
+		reflect.TypeOf(time.Time{}):      ValueTypeTime,
+	}
+)
+
+type (
+	// Binder
+	// build options from struct tags and populate struct fields
+	// with option values of an invocation.
+	//
+	//   type Params struct {
+	//       Addr   string `console:"addr,a,required" desc:"Consul server address" env:"CONSUL_HTTP_ADDR" config:"consul.addr"`
+	//       Scheme string `console:"scheme,s" desc:"Consul server scheme" default:"http" enum:"http,https"`
+	//       Port   int    `console:"port" desc:"Service port" min:"1" max:"65535"`
+	//       Id     string `console:"id" desc:"Service id" regex:"^[a-z0-9-]+$" length:"1,64"`
+	//       Tag    []string          `console:"tag,t" desc:"Service tags" default:"a,b"`
+	//       Meta   map[string]string `console:"meta" desc:"Service meta" default:"k1=v1,k2=v2"`
+	//       Level  int               `console:"verbose,v,count" desc:"Verbose level"`
+	//       Wait   time.Duration     `console:"wait" desc:"Wait timeout" default:"30s"`
+	//       Limit  int64             `console:"limit" desc:"Max body size" default:"10MB" type:"size"`
+	//       File   string            `console:"file" desc:"Definition file" type:"file"`
+	//   }
+	Binder interface {
+		GetOptions() []Option
+		Populate(a Arguments) error
+	}
+
+	binder struct {
+		Fields []*binderField
+		Target reflect.Value
+	}
+
+	binderField struct {
+		Index  []int
+		Option Option
+	}
+)
+
+// NewBinder
+// create and return binder for struct pointer.
+func NewBinder(v interface{}) (Binder, error) {
+	rv := reflect.ValueOf(v)
+
+	// Return error
+	// if not pointer of struct.
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("binding target must be pointer of struct: %T", v)
+	}
+
+	o := &binder{Fields: make([]*binderField, 0), Target: rv}
+	return o, o.scan(rv.Elem().Type(), nil)
+}
+
+// /////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////
+
+func (o *binder) GetOptions() []Option       { return o.getOptions() }
+func (o *binder) Populate(a Arguments) error { return o.populate(a) }
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////
+
+// Return field
+// of target, nil pointer struct is allocated.
+func (o *binder) field(index []int) reflect.Value {
+	v := o.Target.Elem()
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+func (o *binder) getOptions() []Option {
+	list := make([]Option, 0)
+	for _, f := range o.Fields {
+		list = append(list, f.Option)
+	}
+	return list
+}
+
+// Build option
+// by struct field tags.
+func (o *binder) option(f reflect.StructField, tag string) (opt Option, err error) {
+	var (
+		def, hasDefault = f.Tag.Lookup(BindingTagDefault)
+		ss              = strings.Split(tag, ",")
+		vt              ValueType
+	)
+
+	// Value type
+	// of field type or kind.
+	switch t, ok := BindingTypes[f.Type]; {
+	case ok:
+		vt = t
+	case f.Type.Kind() == reflect.String:
+		vt = ValueTypeString
+	case f.Type.Kind() == reflect.Bool:
+		vt = ValueTypeBoolean
+	case f.Type.Kind() >= reflect.Int && f.Type.Kind() <= reflect.Uint64:
+		vt = ValueTypeInteger
+	case f.Type.Kind() == reflect.Float32 || f.Type.Kind() == reflect.Float64:
+		vt = ValueTypeFloat
+	case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+		vt = ValueTypeStringSlice
+	case f.Type.Kind() == reflect.Map && f.Type.Key().Kind() == reflect.String && f.Type.Elem().Kind() == reflect.String:
+		vt = ValueTypeStringMap
+	default:
+		return nil, fmt.Errorf("binding type not supported on field: %s", f.Name)
+	}
+
+	// Option name.
+	if ss[0] = strings.TrimSpace(ss[0]); ss[0] == "" {
+		return nil, fmt.Errorf("binding option name not specified on field: %s", f.Name)
+	}
+
+	// Count
+	// flag on integer field.
+	for i := 2; i < len(ss); i++ {
+		if strings.TrimSpace(ss[i]) == BindingTagCount {
+			if vt != ValueTypeInteger {
+				return nil, fmt.Errorf("binding count flag requires integer field: %s", f.Name)
+			}
+			vt = ValueTypeCount
+		}
+	}
+
+	// Value type
+	// specified by type tag, such as: size, file, host:port.
+	if s := f.Tag.Get(BindingTagType); s != "" {
+		if vt, err = o.valueType(f, vt, s); err != nil {
+			return nil, err
+		}
+	}
+
+	opt = NewOption(ss[0]).SetValueType(vt).SetDescription(f.Tag.Get(BindingTagDescription))
+
+	// Fallback
+	// value sources.
+	if s := f.Tag.Get(BindingTagEnv); s != "" {
+		opt.SetEnv(strings.Split(s, ",")...)
+	}
+	if s := f.Tag.Get(BindingTagConfig); s != "" {
+		opt.SetConfigKey(s)
+	}
+
+	// Short name.
+	if len(ss) > 1 {
+		if s := strings.TrimSpace(ss[1]); len(s) == 1 {
+			opt.SetShortName(s[0])
+		} else if s != "" {
+			return nil, fmt.Errorf("binding short name must be one character on field: %s", f.Name)
+		}
+	}
+
+	// Flags.
+	for i := 2; i < len(ss); i++ {
+		if strings.TrimSpace(ss[i]) == BindingTagRequired {
+			opt.SetMode(ModeRequired)
+		}
+	}
+
+	// Validators.
+	if err = o.validators(f, opt); err != nil {
+		return nil, err
+	}
+
+	// Default value
+	// convert to value type.
+	if hasDefault {
+		var dv interface{}
+		switch vt {
+		case ValueTypeBoolean:
+			dv, err = strconv.ParseBool(def)
+		case ValueTypeFloat:
+			dv, err = strconv.ParseFloat(def, 64)
+		case ValueTypeInteger, ValueTypeCount:
+			dv, err = strconv.ParseInt(def, 10, 64)
+		case ValueTypeStringMap:
+			dv, err = bindingStringMap(def)
+		case ValueTypeStringSlice:
+			dv = bindingStringSlice(def)
+		case ValueTypeCIDR, ValueTypeDuration, ValueTypeHostPort, ValueTypeIP, ValueTypeSize, ValueTypeTime, ValueTypeURL:
+			dv, err = ParseValue(vt, def)
+		default:
+			dv = def
+		}
+		if err != nil {
+			return nil, fmt.Errorf("binding default value convert failed on field: %s", f.Name)
+		}
+		opt.SetDefault(dv)
+	}
+	return
+}
+
+// Assign
+// option values of arguments to struct fields, default values
+// used if option not bound.
+func (o *binder) populate(a Arguments) (err error) {
+	for _, f := range o.Fields {
+		var (
+			fv  = o.field(f.Index)
+			opt = f.Option
+		)
+
+		if x := a.GetOption(opt.GetName()); x != nil {
+			opt = x
+		}
+
+		switch opt.GetValueType() {
+		case ValueTypeBoolean:
+			var v bool
+			if v, err = opt.ToBool(); err == nil {
+				fv.SetBool(v)
+			}
+		case ValueTypeFloat:
+			var v float64
+			if v, err = opt.ToFloat(); err == nil {
+				fv.SetFloat(v)
+			}
+		case ValueTypeInteger:
+			var v int64
+			if v, err = opt.ToInt(); err == nil {
+				if fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint64 {
+					fv.SetUint(uint64(v))
+				} else {
+					fv.SetInt(v)
+				}
+			}
+		case ValueTypeCIDR, ValueTypeIP, ValueTypeTime, ValueTypeURL:
+			var v interface{}
+			if v, err = o.typed(opt); err == nil && v != nil {
+				fv.Set(reflect.ValueOf(v))
+			}
+		case ValueTypeDuration:
+			var v time.Duration
+			if v, err = opt.ToDuration(); err == nil {
+				fv.SetInt(int64(v))
+			}
+		case ValueTypeSize:
+			var v int64
+			if v, err = opt.ToSize(); err == nil {
+				if fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint64 {
+					fv.SetUint(uint64(v))
+				} else {
+					fv.SetInt(v)
+				}
+			}
+		case ValueTypeDir, ValueTypeFile:
+			var v string
+			if v, err = opt.ToPath(); err == nil {
+				fv.SetString(v)
+			}
+		case ValueTypeHostPort:
+			var v string
+			if v, err = opt.ToHostPort(); err == nil {
+				fv.SetString(v)
+			}
+		case ValueTypeCount:
+			var v int
+			if v, err = opt.ToCount(); err == nil {
+				if fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint64 {
+					fv.SetUint(uint64(v))
+				} else {
+					fv.SetInt(int64(v))
+				}
+			}
+		case ValueTypeStringMap:
+			var v map[string]string
+			if v, err = opt.ToStringMap(); err == nil {
+				fv.Set(reflect.ValueOf(v))
+			}
+		case ValueTypeStringSlice:
+			var v []string
+			if v, err = opt.ToStringSlice(); err == nil {
+				fv.Set(reflect.ValueOf(v))
+			}
+		default:
+			var v string
+			if v, err = opt.ToString(); err == nil {
+				fv.SetString(v)
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+	return nil
+}
+
+// Scan
+// struct fields recursively.
+func (o *binder) scan(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f        = t.Field(i)
+			fi       = append(append([]int{}, index...), i)
+			ft       = f.Type
+			tag, has = f.Tag.Lookup(BindingTagName)
+		)
+
+		// Ignore
+		// unexported or skipped fields.
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		// Nested
+		// or embedded struct without tag.
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if !has {
+			if ft.Kind() == reflect.Struct {
+				if err := o.scan(ft, fi); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// Build option.
+		opt, err := o.option(f, tag)
+		if err != nil {
+			return err
+		}
+		o.Fields = append(o.Fields, &binderField{Index: fi, Option: opt})
+	}
+	return nil
+}
+
+// Typed value
+// of option for pointer, slice and struct fields.
+func (o *binder) typed(opt Option) (interface{}, error) {
+	switch opt.GetValueType() {
+	case ValueTypeCIDR:
+		return opt.ToCIDR()
+	case ValueTypeIP:
+		return opt.ToIP()
+	case ValueTypeTime:
+		return opt.ToTime()
+	case ValueTypeURL:
+		return opt.ToURL()
+	}
+	return nil, nil
+}
+
+// Add validators
+// of enum, min, max, regex and length tags.
+func (o *binder) validators(f reflect.StructField, opt Option) error {
+	if s := f.Tag.Get(BindingTagEnum); s != "" {
+		opt.SetEnum(bindingStringSlice(s)...)
+	}
+
+	// Numeric range,
+	// unlimited if one side not specified.
+	min, hasMin := f.Tag.Lookup(BindingTagMin)
+	max, hasMax := f.Tag.Lookup(BindingTagMax)
+	if hasMin || hasMax {
+		var (
+			err    error
+			lo, hi = math.Inf(-1), math.Inf(1)
+		)
+		if hasMin {
+			if lo, err = strconv.ParseFloat(min, 64); err != nil {
+				return fmt.Errorf("binding min value convert failed on field: %s", f.Name)
+			}
+		}
+		if hasMax {
+			if hi, err = strconv.ParseFloat(max, 64); err != nil {
+				return fmt.Errorf("binding max value convert failed on field: %s", f.Name)
+			}
+		}
+		opt.AddValidator(ValidateRange(lo, hi))
+	}
+
+	if s := f.Tag.Get(BindingTagRegex); s != "" {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return fmt.Errorf("binding regex compile failed on field: %s", f.Name)
+		}
+		opt.AddValidator(ValidateRegex(re))
+	}
+
+	// Length
+	// of min or min,max.
+	if s := f.Tag.Get(BindingTagLength); s != "" {
+		var (
+			err    error
+			lo, hi int
+			ls     = strings.Split(s, ",")
+		)
+		if lo, err = strconv.Atoi(strings.TrimSpace(ls[0])); err == nil && len(ls) > 1 {
+			hi, err = strconv.Atoi(strings.TrimSpace(ls[1]))
+		}
+		if err != nil {
+			return fmt.Errorf("binding length convert failed on field: %s", f.Name)
+		}
+		opt.AddValidator(ValidateLength(lo, hi))
+	}
+	return nil
+}
+
+// Value type
+// of type tag, must be compatible with field kind.
+func (o *binder) valueType(f reflect.StructField, vt ValueType, name string) (ValueType, error) {
+	for k, s := range ValueTypeText {
+		if s != name {
+			continue
+		}
+		switch {
+		case k == ValueTypeSize && vt == ValueTypeInteger,
+			(k == ValueTypeDir || k == ValueTypeFile || k == ValueTypeHostPort) && vt == ValueTypeString,
+			k == ValueTypeCount && vt == ValueTypeInteger:
+			return k, nil
+		}
+		return vt, fmt.Errorf("binding type %s not supported on field: %s", name, f.Name)
+	}
+	return vt, fmt.Errorf("binding type not recognized on field: %s: %s", f.Name, name)
+}
+
+// Parse
+// comma separated key=value pairs.
+func bindingStringMap(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, x := range bindingStringSlice(s) {
+		kv := strings.SplitN(x, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("key=value pair expected: %s", x)
+		}
+		res[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return res, nil
+}
+
+// Parse
+// comma separated words, empty words ignored.
+func bindingStringSlice(s string) []string {
+	res := make([]string, 0)
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			res = append(res, x)
+		}
+	}
+	return res
+}
@@ -4,8 +4,8 @@
 package managers
 
 import (
+	"context"
 	"fmt"
-	"runtime"
 	"strings"
 )
 
@@ -13,58 +13,186 @@ type (
 	// Command
 	// operation interface.
 	Command interface {
+		AddCommand(cs ...Command) error
 		AddOption(opts ...Option) error
+		AddOptionGroup(gs ...OptionGroup) error
+		AddPersistentOption(opts ...Option) error
+		AddPositional(ps ...Positional) error
+		BindPersistentStruct(v interface{}) error
+		BindStruct(v interface{}) error
+		GetAliases() []string
+		GetCommand(key string) Command
+		GetCommands() map[string]Command
+		GetContextHandler() ContextHandler
 		GetDescription() string
+		GetHandler() CommandHandler
 		GetHidden() bool
+		GetMiddlewares() []Middleware
 		GetName() string
 		GetOption(key string) Option
+		GetOptionGroups() []OptionGroup
 		GetOptions() map[string]Option
+		GetParent() Command
+		GetPath() string
+		GetPersistentOptions() map[string]Option
+		GetPositionals() []Positional
+		IsGroup() bool
 		Run(manager Manager, arguments Arguments) error
+		RunContext(ctx context.Context, manager Manager, arguments Arguments) error
+		SetAliases(ss ...string) Command
+		SetContextHandler(handler ContextHandler) Command
 		SetDescription(s string) Command
 		SetHandler(handler CommandHandler) Command
 		SetHidden(b bool) Command
+		SetOnError(handler ErrorHandler) Command
+		SetParent(c Command) Command
+		SetPostRun(handler CommandHandler) Command
+		SetPreRun(handler CommandHandler) Command
+		Use(ms ...Middleware) Command
 	}
 
 	// CommandHandler
 	// callable handler on command.
 	CommandHandler func(manager Manager, arguments Arguments) error
 
+	// ContextHandler
+	// callable handler on command with context, the context is
+	// cancelled when SIGINT or SIGTERM received.
+	ContextHandler func(ctx context.Context, manager Manager, arguments Arguments) error
+
 	command struct {
+		Aliases           []string
+		CommandKeys       map[string]string
+		CommandMapper     map[string]Command
+		ContextHandler    ContextHandler
 		Handler           CommandHandler
 		Hidden            bool
+		Middlewares       []Middleware
 		Name, Description string
+		OnError           ErrorHandler
+		OptionGroups      []OptionGroup
 		OptionKeys        map[string]string
 		OptionMapper      map[string]Option
+		Parent            Command
+		PersistentOptions map[string]Option
+		Positionals       []Positional
+		PostRun, PreRun   CommandHandler
 	}
 )
 
 func NewCommand(name string) Command {
 	return (&command{
-		Name:         name,
-		OptionKeys:   make(map[string]string),
-		OptionMapper: make(map[string]Option),
+		Aliases:       make([]string, 0),
+		CommandKeys:   make(map[string]string),
+		CommandMapper: make(map[string]Command),
+		Middlewares:   make([]Middleware, 0),
+		Name:          name,
+		OptionGroups:  make([]OptionGroup, 0),
+		OptionKeys:    make(map[string]string),
+		OptionMapper:  make(map[string]Option),
+		Positionals:   make([]Positional, 0),
+
+		PersistentOptions: make(map[string]Option),
 	}).initFields()
 }
 
+// NewCommandGroup
+// create command group and add children built by constructors.
+func NewCommandGroup(name, description string, constructors ...func() (Command, error)) (c Command, err error) {
+	var child Command
+
+	c = NewCommand(name).SetDescription(description)
+
+	// Create and add
+	// children to command group.
+	for _, f := range constructors {
+		if child, err = f(); err != nil {
+			return
+		}
+		if err = c.AddCommand(child); err != nil {
+			return
+		}
+	}
+	return
+}
+
 // /////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////
 
-func (o *command) AddOption(opts ...Option) error            { return o.addOption(opts) }
-func (o *command) GetDescription() string                    { return o.Description }
-func (o *command) GetHidden() bool                           { return o.Hidden }
-func (o *command) GetName() string                           { return o.Name }
-func (o *command) GetOption(key string) Option               { return o.getOption(key) }
-func (o *command) GetOptions() map[string]Option             { return o.OptionMapper }
-func (o *command) Run(m Manager, a Arguments) error          { return o.run(m, a) }
-func (o *command) SetDescription(s string) Command           { o.Description = s; return o }
-func (o *command) SetHandler(handler CommandHandler) Command { o.Handler = handler; return o }
-func (o *command) SetHidden(b bool) Command                  { o.Hidden = b; return o }
+func (o *command) AddCommand(cs ...Command) error           { return o.addCommand(cs) }
+func (o *command) AddOption(opts ...Option) error           { return o.addOption(opts) }
+func (o *command) AddOptionGroup(gs ...OptionGroup) error   { return o.addOptionGroup(gs) }
+func (o *command) AddPersistentOption(opts ...Option) error { return o.addPersistentOption(opts) }
+func (o *command) AddPositional(ps ...Positional) error     { return o.addPositional(ps) }
+func (o *command) BindPersistentStruct(v interface{}) error { return o.bindPersistentStruct(v) }
+func (o *command) BindStruct(v interface{}) error           { return o.bindStruct(v) }
+func (o *command) GetAliases() []string                     { return o.Aliases }
+func (o *command) GetCommand(key string) Command            { return o.getCommand(key) }
+func (o *command) GetCommands() map[string]Command          { return o.CommandMapper }
+func (o *command) GetContextHandler() ContextHandler        { return o.ContextHandler }
+func (o *command) GetDescription() string                   { return o.Description }
+func (o *command) GetHandler() CommandHandler               { return o.Handler }
+func (o *command) GetHidden() bool                          { return o.Hidden }
+func (o *command) GetMiddlewares() []Middleware             { return o.Middlewares }
+func (o *command) GetName() string                          { return o.Name }
+func (o *command) GetOption(key string) Option              { return o.getOption(key) }
+func (o *command) GetOptionGroups() []OptionGroup           { return o.OptionGroups }
+func (o *command) GetOptions() map[string]Option            { return o.OptionMapper }
+func (o *command) GetParent() Command                       { return o.Parent }
+func (o *command) GetPath() string                          { return o.getPath() }
+func (o *command) GetPersistentOptions() map[string]Option  { return o.PersistentOptions }
+func (o *command) GetPositionals() []Positional             { return o.Positionals }
+func (o *command) IsGroup() bool                            { return len(o.CommandMapper) > 0 }
+func (o *command) Run(m Manager, a Arguments) error         { return o.run(context.Background(), m, a) }
+func (o *command) RunContext(ctx context.Context, m Manager, a Arguments) error {
+	return o.run(ctx, m, a)
+}
+func (o *command) SetAliases(ss ...string) Command            { o.setAliases(ss); return o }
+func (o *command) SetContextHandler(h ContextHandler) Command { o.ContextHandler = h; return o }
+func (o *command) SetDescription(s string) Command            { o.Description = s; return o }
+func (o *command) SetHandler(handler CommandHandler) Command  { o.Handler = handler; return o }
+func (o *command) SetHidden(b bool) Command                   { o.Hidden = b; return o }
+func (o *command) SetOnError(h ErrorHandler) Command          { o.OnError = h; return o }
+func (o *command) SetParent(c Command) Command                { o.Parent = c; return o }
+func (o *command) SetPostRun(h CommandHandler) Command        { o.PostRun = h; return o }
+func (o *command) SetPreRun(h CommandHandler) Command         { o.PreRun = h; return o }
+func (o *command) Use(ms ...Middleware) Command               { o.use(ms); return o }
 
 // /////////////////////////////////////////////////////////////
 // Access and constructor
 // /////////////////////////////////////////////////////////////
 
+func (o *command) addCommand(cs []Command) error {
+	for _, c := range cs {
+		if c == nil {
+			continue
+		}
+
+		// Name required.
+		if c.GetName() == "" {
+			return fmt.Errorf("can not add unnamed command to command: %s", o.getPath())
+		}
+
+		// Add twice.
+		for _, k := range append([]string{c.GetName()}, c.GetAliases()...) {
+			if _, ok := o.CommandKeys[k]; ok {
+				return fmt.Errorf("command exists in command %s: %s", o.getPath(), k)
+			}
+		}
+
+		// Set mapper.
+		o.CommandMapper[c.GetName()] = c.SetParent(o)
+
+		// Full name and alias mapper.
+		o.CommandKeys[c.GetName()] = c.GetName()
+		for _, k := range c.GetAliases() {
+			o.CommandKeys[k] = c.GetName()
+		}
+	}
+	return nil
+}
+
 func (o *command) addOption(opts []Option) error {
 	for _, opt := range opts {
 		if opt == nil {
@@ -85,6 +213,117 @@ func (o *command) addOption(opts []Option) error {
 	return nil
 }
 
+// Add constraint
+// groups, options in group must be added before.
+func (o *command) addOptionGroup(gs []OptionGroup) error {
+	for _, g := range gs {
+		if g == nil {
+			continue
+		}
+
+		// Two options
+		// at least.
+		if len(g.GetNames()) < 2 {
+			return fmt.Errorf("option group requires two options at least in command: %s", o.getPath())
+		}
+
+		// Registered
+		// as option or persistent option.
+		for _, name := range g.GetNames() {
+			_, ok := o.OptionMapper[name]
+			if _, persistent := o.PersistentOptions[name]; !ok && !persistent {
+				return fmt.Errorf("option not registered in command %s: %s", o.getPath(), name)
+			}
+		}
+
+		o.OptionGroups = append(o.OptionGroups, g)
+	}
+	return nil
+}
+
+// Add options
+// inherited by command and all descendants.
+func (o *command) addPersistentOption(opts []Option) error {
+	for _, opt := range opts {
+		if opt != nil {
+			o.PersistentOptions[opt.GetName()] = opt
+		}
+	}
+	return nil
+}
+
+func (o *command) addPositional(ps []Positional) error {
+	for _, p := range ps {
+		if p == nil {
+			continue
+		}
+
+		// Name required.
+		if p.GetName() == "" {
+			return fmt.Errorf("can not add unnamed argument to command: %s", o.getPath())
+		}
+
+		// Compare with
+		// previous arguments.
+		for _, x := range o.Positionals {
+			if x.GetName() == p.GetName() {
+				return fmt.Errorf("argument exists in command %s: %s", o.getPath(), p.GetName())
+			}
+			if x.IsVariadic() {
+				return fmt.Errorf("argument can not follow variadic argument: %s", p.GetName())
+			}
+			if x.GetMode() == ModeOptional && p.GetMode() == ModeRequired {
+				return fmt.Errorf("required argument can not follow optional argument: %s", p.GetName())
+			}
+		}
+
+		o.Positionals = append(o.Positionals, p)
+	}
+	return nil
+}
+
+// Build options
+// with struct tags, values are read in handler by populating a
+// new struct with Arguments.Populate.
+func (o *command) bindStruct(v interface{}) error {
+	b, err := NewBinder(v)
+	if err != nil {
+		return err
+	}
+	return o.addOption(b.GetOptions())
+}
+
+// Build persistent options
+// with struct tags, such as shared config of command group.
+func (o *command) bindPersistentStruct(v interface{}) error {
+	b, err := NewBinder(v)
+	if err != nil {
+		return err
+	}
+	return o.addPersistentOption(b.GetOptions())
+}
+
+func (o *command) getCommand(key string) (c Command) {
+	for i, s := range strings.Fields(key) {
+		if i == 0 {
+			// Child of current.
+			if k, exists := o.CommandKeys[s]; exists {
+				c = o.CommandMapper[k]
+			}
+		} else {
+			// Child of child.
+			c = c.GetCommand(s)
+		}
+
+		// Return nil
+		// if path not matched.
+		if c == nil {
+			return nil
+		}
+	}
+	return
+}
+
 func (o *command) getOption(key string) Option {
 	if k, exists := o.OptionKeys[key]; exists {
 		if v, ok := o.OptionMapper[k]; ok {
@@ -94,37 +333,75 @@ func (o *command) getOption(key string) Option {
 	return nil
 }
 
+func (o *command) getPath() string {
+	if o.Parent != nil {
+		return fmt.Sprintf("%s %s", o.Parent.GetPath(), o.Name)
+	}
+	return o.Name
+}
+
 func (o *command) initFields() *command {
 	return o
 }
 
-func (o *command) run(m Manager, a Arguments) (err error) {
-	if o.Handler == nil {
+// Middlewares
+// of command and its ancestors, middlewares of command group are
+// applied to all descendants and the root one is the outermost.
+func (o *command) middlewares() []Middleware {
+	ms := append([]Middleware{}, o.Middlewares...)
+	for p := o.Parent; p != nil; p = p.GetParent() {
+		ms = append(append([]Middleware{}, p.GetMiddlewares()...), ms...)
+	}
+	return ms
+}
+
+func (o *command) run(ctx context.Context, m Manager, a Arguments) (err error) {
+	if o.Handler == nil && o.ContextHandler == nil {
 		err = fmt.Errorf("command handler not defined: %s", o.Name)
 		return
 	}
 
 	// Catch
-	// command runner panic.
+	// middleware and hook panic.
 	defer func() {
 		if r := recover(); r != nil {
-			es := []string{
-				fmt.Sprintf("command panic on %v: %v", o.Name, r),
-			}
+			err = recoverError(o.Name, r)
+		}
+	}()
 
-			for i := 0; ; i++ {
-				if _, f, l, g := runtime.Caller(i); g {
-					es = append(es, fmt.Sprintf("%s:%d", strings.TrimSpace(f), l))
-					continue
-				}
-				break
+	// Call handler
+	// in middlewares and hooks.
+	err = chainHandler(func(m Manager, a Arguments) (err error) {
+		// Catch
+		// command runner panic, middlewares receive it as error.
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverError(o.Name, r)
 			}
+		}()
 
-			err = fmt.Errorf("%s", strings.Join(es, "\n"))
+		if o.ContextHandler != nil {
+			return o.ContextHandler(ctx, m, a)
 		}
-	}()
-
-	// Call handler.
-	err = o.Handler(m, a)
+		return o.Handler(m, a)
+	}, o.middlewares(), o.PreRun, o.PostRun, o.OnError)(m, a)
 	return
 }
+
+func (o *command) setAliases(ss []string) {
+	as := make([]string, 0)
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			as = append(as, s)
+		}
+	}
+	o.Aliases = as
+}
+
+func (o *command) use(ms []Middleware) {
+	for _, m := range ms {
+		if m != nil {
+			o.Middlewares = append(o.Middlewares, m)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-20
+
+package managers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// Config
+	// operation interface for config file loaded by manager.
+	//
+	//   consul:
+	//     addr: 127.0.0.1:8500
+	//     scheme: https
+	Config interface {
+		// Get
+		// return value by dotted key, such as: consul.addr.
+		Get(key string) (interface{}, bool)
+
+		// GetPath
+		// return config file path.
+		GetPath() string
+
+		// GetString
+		// return value as string by dotted key.
+		GetString(key string) (string, bool)
+	}
+
+	config struct {
+		Data map[string]interface{}
+		Path string
+	}
+)
+
+// LoadConfig
+// read and parse config file, accept: .json, .toml, .yaml, .yml.
+func LoadConfig(path string) (Config, error) {
+	var (
+		buf []byte
+		err error
+		o   = &config{Data: make(map[string]interface{}), Path: path}
+	)
+
+	// Read file.
+	if buf, err = os.ReadFile(path); err != nil {
+		return nil, err
+	}
+
+	// Parse
+	// by file extension.
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(buf, &o.Data)
+	case ".toml":
+		err = toml.Unmarshal(buf, &o.Data)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buf, &o.Data)
+	default:
+		err = fmt.Errorf("config file type not supported: %s", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("config file parse failed: %s: %v", path, err)
+	}
+	return o, nil
+}
+
+// /////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////
+
+func (o *config) Get(key string) (interface{}, bool)  { return o.get(key) }
+func (o *config) GetPath() string                     { return o.Path }
+func (o *config) GetString(key string) (string, bool) { return o.getString(key) }
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////
+
+func (o *config) get(key string) (v interface{}, ok bool) {
+	v = o.Data
+
+	// Range
+	// dotted key segments.
+	for _, k := range strings.Split(key, ".") {
+		switch m := v.(type) {
+		case map[string]interface{}:
+			v, ok = m[k]
+		case map[interface{}]interface{}:
+			v, ok = m[k]
+		default:
+			ok = false
+		}
+
+		if !ok {
+			return nil, false
+		}
+	}
+	return
+}
+
+func (o *config) getString(key string) (string, bool) {
+	if v, ok := o.get(key); ok && v != nil {
+		switch v.(type) {
+		case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+			return "", false
+		}
+		return fmt.Sprintf("%v", v), true
+	}
+	return "", false
+}
@@ -0,0 +1,164 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	ErrorKindFailure ErrorKind = iota
+	ErrorKindUsage
+	ErrorKindValidation
+	ErrorKindNotFound
+	ErrorKindRemote
+	ErrorKindPanic
+	ErrorKindInterrupted
+)
+
+var (
+	// ErrorKindCodes
+	// process exit code of error kind.
+	ErrorKindCodes = map[ErrorKind]int{
+		ErrorKindFailure:     ExitCodeFailure,
+		ErrorKindUsage:       ExitCodeUsage,
+		ErrorKindValidation:  ExitCodeValidation,
+		ErrorKindNotFound:    ExitCodeNotFound,
+		ErrorKindRemote:      ExitCodeRemote,
+		ErrorKindPanic:       ExitCodePanic,
+		ErrorKindInterrupted: ExitCodeSignal,
+	}
+)
+
+type (
+	// Error
+	// typed error with exit code and optional hint, hint is printed
+	// after error message and usage of command is printed for usage
+	// error.
+	//
+	//   return managers.NewNotFoundError("key not found: %s", key).
+	//       SetHint("Run 'demo consul kv upload' to create it")
+	Error interface {
+		error
+		GetCode() int
+		GetHint() string
+		GetKind() ErrorKind
+		SetHint(s string) Error
+	}
+
+	// ErrorKind
+	// category of typed error.
+	ErrorKind int
+
+	typedError struct {
+		Err  error
+		Hint string
+		Kind ErrorKind
+		Path string
+	}
+)
+
+// ErrorCode
+// return process exit code of error, typed error in chain is
+// used, or ExitCodeFailure for other errors.
+func ErrorCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	var e Error
+	if errors.As(err, &e) {
+		return e.GetCode()
+	}
+	return ExitCodeFailure
+}
+
+// ErrorHint
+// return hint of typed error in chain.
+func ErrorHint(err error) string {
+	var e Error
+	if errors.As(err, &e) {
+		return e.GetHint()
+	}
+	return ""
+}
+
+// NewError
+// create typed error of kind, format accept %w verb.
+func NewError(kind ErrorKind, format string, args ...interface{}) Error {
+	return &typedError{Err: fmt.Errorf(format, args...), Kind: kind}
+}
+
+// NewNotFoundError
+// create error for resource not found, such as key or file.
+func NewNotFoundError(format string, args ...interface{}) Error {
+	return NewError(ErrorKindNotFound, format, args...)
+}
+
+// NewRemoteError
+// create error for remote api failure, return nil if err is nil.
+func NewRemoteError(err error) Error {
+	return WrapError(ErrorKindRemote, err)
+}
+
+// NewUsageError
+// create error for incorrect invocation, such as unknown command
+// or option, usage of command is printed with error message.
+func NewUsageError(format string, args ...interface{}) Error {
+	return NewError(ErrorKindUsage, format, args...)
+}
+
+// NewValidationError
+// create error for invalid option or argument value.
+func NewValidationError(format string, args ...interface{}) Error {
+	return NewError(ErrorKindValidation, format, args...)
+}
+
+// WrapError
+// return typed error of kind wrapping err, err is returned as is if
+// it's typed already, return nil if err is nil.
+func WrapError(kind ErrorKind, err error) Error {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(Error); ok {
+		return e
+	}
+	return &typedError{Err: err, Kind: kind}
+}
+
+// /////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////
+
+func (o *typedError) Error() string          { return o.Err.Error() }
+func (o *typedError) GetCode() int           { return o.getCode() }
+func (o *typedError) GetHint() string        { return o.Hint }
+func (o *typedError) GetKind() ErrorKind     { return o.Kind }
+func (o *typedError) SetHint(s string) Error { o.Hint = s; return o }
+func (o *typedError) Unwrap() error          { return o.Err }
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////
+
+func (o *typedError) getCode() int {
+	if c, ok := ErrorKindCodes[o.Kind]; ok {
+		return c
+	}
+	return ExitCodeFailure
+}
+
+// Usage path
+// of command, set by manager if usage error returned when running
+// command.
+func usagePath(c Command, err error) error {
+	var e *typedError
+	if c != nil && errors.As(err, &e) && e.Kind == ErrorKindUsage && e.Path == "" {
+		e.Path = c.GetPath()
+	}
+	return err
+}
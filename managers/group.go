@@ -0,0 +1,161 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	OptionGroupExclusive OptionGroupKind = iota
+	OptionGroupRequires
+	OptionGroupRequiredOneOf
+)
+
+type (
+	// OptionGroup
+	// constraint between options of command, an option is
+	// specified if assigned by flag, env or config file, and
+	// flag overrides env or config file in exclusive group.
+	//
+	//   c.AddOptionGroup(
+	//       managers.NewExclusiveGroup("token", "token-file"),
+	//       managers.NewRequiresGroup("cert-file", "key-file"),
+	//       managers.NewRequiredOneOfGroup("path", "stdin"),
+	//   )
+	OptionGroup interface {
+		GetKind() OptionGroupKind
+		GetNames() []string
+		GetTitle() string
+		SetTitle(s string) OptionGroup
+		Validate(a Arguments) error
+	}
+
+	// OptionGroupKind
+	// constraint kind of option group.
+	OptionGroupKind int
+
+	optionGroup struct {
+		Kind  OptionGroupKind
+		Names []string
+		Title string
+	}
+)
+
+// NewExclusiveGroup
+// create option group, at most one of options can be specified.
+func NewExclusiveGroup(names ...string) OptionGroup {
+	return &optionGroup{Kind: OptionGroupExclusive, Names: names}
+}
+
+// NewRequiredOneOfGroup
+// create option group, exactly one of options must be specified.
+func NewRequiredOneOfGroup(names ...string) OptionGroup {
+	return &optionGroup{Kind: OptionGroupRequiredOneOf, Names: names}
+}
+
+// NewRequiresGroup
+// create option group, other options must be specified if the
+// first option specified.
+func NewRequiresGroup(name string, requires ...string) OptionGroup {
+	return &optionGroup{Kind: OptionGroupRequires, Names: append([]string{name}, requires...)}
+}
+
+// /////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////
+
+func (o *optionGroup) GetKind() OptionGroupKind      { return o.Kind }
+func (o *optionGroup) GetNames() []string            { return o.Names }
+func (o *optionGroup) GetTitle() string              { return o.getTitle() }
+func (o *optionGroup) SetTitle(s string) OptionGroup { o.Title = s; return o }
+func (o *optionGroup) Validate(a Arguments) error    { return o.validate(a) }
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////
+
+// Title
+// used as help section header.
+//
+//   Mutually exclusive options
+//   Option --cert-file requires --key-file
+//   Exactly one of options required
+func (o *optionGroup) getTitle() string {
+	if o.Title != "" {
+		return o.Title
+	}
+
+	switch o.Kind {
+	case OptionGroupExclusive:
+		return "Mutually exclusive options"
+	case OptionGroupRequires:
+		return fmt.Sprintf("Option %s requires %s", o.text(o.Names[:1]), o.text(o.Names[1:]))
+	}
+	return "Exactly one of options required"
+}
+
+// Specified
+// option names of invocation. For exclusive and required one of
+// group, options assigned from source of lower precedence are
+// reset if another one assigned from higher, such as token from
+// env and token file from flag.
+//
+//   flag > env > config file
+func (o *optionGroup) specified(a Arguments) []string {
+	var (
+		opts = make([]Option, 0)
+		ss   = make([]string, 0)
+		top  = ValueSourceDefault
+	)
+
+	for _, name := range o.Names {
+		if opt := a.GetOption(name); opt != nil && opt.Assigned() {
+			if top == ValueSourceDefault || opt.GetSource() < top {
+				top = opt.GetSource()
+			}
+			opts = append(opts, opt)
+		}
+	}
+
+	for _, opt := range opts {
+		if o.Kind != OptionGroupRequires && opt.GetSource() != top {
+			if x, ok := opt.(*option); ok {
+				x.reset()
+			}
+			continue
+		}
+		ss = append(ss, opt.GetName())
+	}
+	return ss
+}
+
+func (o *optionGroup) text(names []string) string {
+	ss := make([]string, 0)
+	for _, name := range names {
+		ss = append(ss, "--"+name)
+	}
+	return strings.Join(ss, ", ")
+}
+
+func (o *optionGroup) validate(a Arguments) error {
+	ss := o.specified(a)
+
+	switch o.Kind {
+	case OptionGroupExclusive:
+		if len(ss) > 1 {
+			return fmt.Errorf("options are mutually exclusive: %s", o.text(ss))
+		}
+	case OptionGroupRequires:
+		if len(ss) > 0 && ss[0] == o.Names[0] && len(ss) < len(o.Names) {
+			return fmt.Errorf("option %s requires %s", o.text(o.Names[:1]), o.text(o.Names[1:]))
+		}
+	case OptionGroupRequiredOneOf:
+		if len(ss) != 1 {
+			return fmt.Errorf("exactly one of options required: %s", o.text(o.Names))
+		}
+	}
+	return nil
+}
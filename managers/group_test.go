@@ -0,0 +1,79 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+)
+
+type testGroupParams struct {
+	Cert      string `console:"cert-file" desc:"Cert" env:"TEST_GROUP_CERT"`
+	Key       string `console:"key-file" desc:"Key" env:"TEST_GROUP_KEY"`
+	Token     string `console:"token" desc:"Token" env:"TEST_GROUP_TOKEN"`
+	TokenFile string `console:"token-file" desc:"Token file" env:"TEST_GROUP_TOKEN_FILE"`
+}
+
+func TestOptionGroupSource(t *testing.T) {
+	m := NewManager()
+	c := NewCommand("token")
+	if err := c.BindStruct(&testGroupParams{}); err != nil {
+		t.Fatalf("bind struct failed: %v", err)
+	}
+	if err := c.AddOptionGroup(
+		NewExclusiveGroup("token", "token-file"),
+		NewRequiresGroup("cert-file", "key-file"),
+	); err != nil {
+		t.Fatalf("add option group failed: %v", err)
+	}
+	c.SetHandler(func(m Manager, a Arguments) error {
+		p := &testGroupParams{}
+		if err := a.Populate(p); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(a.GetIO().Out, "%s|%s|%s|%s", p.Token, p.TokenFile, p.Cert, p.Key)
+		return err
+	})
+	if err := m.AddCommand(c); err != nil {
+		t.Fatalf("add command failed: %v", err)
+	}
+
+	for _, x := range []struct {
+		Env    map[string]string
+		Args   []string
+		Expect string
+		Code   int
+	}{
+		{Args: []string{"--token=t"}, Expect: "t|||"},
+		{Args: []string{"--token=t", "--token-file=f"}, Code: ExitCodeValidation},
+		{Env: map[string]string{"TEST_GROUP_TOKEN": "e"}, Expect: "e|||"},
+		{Env: map[string]string{"TEST_GROUP_TOKEN": "e"}, Args: []string{"--token-file=f"}, Expect: "|f||"},
+		{Env: map[string]string{"TEST_GROUP_TOKEN": "e"}, Args: []string{"--token=t"}, Expect: "t|||"},
+		{Env: map[string]string{"TEST_GROUP_TOKEN": "e", "TEST_GROUP_TOKEN_FILE": "g"}, Code: ExitCodeValidation},
+		{Env: map[string]string{"TEST_GROUP_TOKEN": "e", "TEST_GROUP_TOKEN_FILE": "g"}, Args: []string{"--token-file=f"}, Expect: "|f||"},
+		{Args: []string{"--cert-file=c"}, Code: ExitCodeValidation},
+		{Env: map[string]string{"TEST_GROUP_KEY": "k"}, Args: []string{"--cert-file=c"}, Expect: "||c|k"},
+	} {
+		for k, v := range x.Env {
+			if err := os.Setenv(k, v); err != nil {
+				t.Fatalf("set env failed: %v", err)
+			}
+		}
+
+		var out bytes.Buffer
+		res := m.Execute(context.Background(), &IO{Out: &out, Err: &bytes.Buffer{}}, append([]string{"demo", "token"}, x.Args...)...)
+		if res.Code != x.Code || (x.Code == ExitCodeSuccess && out.String() != x.Expect) {
+			t.Errorf("env: %v, args: %v, code: %d, output: %q, error: %v", x.Env, x.Args, res.Code, out.String(), res.Err)
+		}
+
+		for k := range x.Env {
+			if err := os.Unsetenv(k); err != nil {
+				t.Fatalf("unset env failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-19
+
+package managers
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	ExitCodeSuccess    = 0
+	ExitCodeFailure    = 1
+	ExitCodeUsage      = 2
+	ExitCodeValidation = 3
+	ExitCodeNotFound   = 4
+	ExitCodeRemote     = 5
+	ExitCodePanic      = 6
+)
+
+type (
+	// IO
+	// standard streams used by manager and commands.
+	IO struct {
+		In  io.Reader
+		Out io.Writer
+		Err io.Writer
+	}
+
+	// Result
+	// returned by manager execution.
+	Result struct {
+		Code int
+		Err  error
+	}
+)
+
+// NewIO
+// create and return standard streams of process.
+func NewIO() *IO {
+	return &IO{
+		In:  os.Stdin,
+		Out: os.Stdout,
+		Err: os.Stderr,
+	}
+}
+
+// NewResult
+// create and return result with exit code of error.
+func NewResult(err error) *Result {
+	return &Result{Code: ErrorCode(err), Err: err}
+}
@@ -4,55 +4,148 @@
 package managers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 const (
 	Version = "3.0.0"
+
+	// DefaultGracePeriod
+	// wait for handler return after context cancelled by signal.
+	DefaultGracePeriod = time.Second * 5
+
+	// ExitCodeSignal
+	// exit code if handler not return in grace period.
+	ExitCodeSignal = 130
 )
 
 type (
 	// Manager
 	// operation interface.
 	Manager interface {
+		AddAlias(alias, path string) error
 		AddCommand(c Command) error
+		AddOption(opts ...Option) error
+		BindStruct(v interface{}) error
+		Execute(ctx context.Context, x *IO, ss ...string) *Result
 		GetCommand(key string) Command
 		GetCommands() map[string]Command
+		GetConfig() (Config, error)
 		GetDescription() string
+		GetGracePeriod() time.Duration
+		GetIO() *IO
+		GetInheritedOptions(c Command) []Option
+		GetOptions() map[string]Option
 		Run(a Arguments) error
+		RunContext(ctx context.Context, a Arguments) error
 		RunTerminal() error
+		SetAbbreviation(b bool) Manager
+		SetConfigFile(path string) Manager
 		SetDescription(s string) Manager
+		SetGracePeriod(d time.Duration) Manager
+		SetIO(x *IO) Manager
+		SetOnError(handler ErrorHandler) Manager
+		SetPostRun(handler CommandHandler) Manager
+		SetPreRun(handler CommandHandler) Manager
+		Use(ms ...Middleware) Manager
 	}
 
 	manager struct {
-		Commands    map[string]Command
-		Description string
+		Abbreviation bool
+		Aliases      map[string]string
+		CommandKeys  map[string]string
+		Commands     map[string]Command
+		Config       Config
+		ConfigFile   string
+		Description  string
+		GracePeriod  time.Duration
+		IO           *IO
+		Middlewares  []Middleware
+		OnError      ErrorHandler
+		Options      map[string]Option
+		PostRun      CommandHandler
+		PreRun       CommandHandler
+
+		mu sync.Mutex
 	}
 )
 
 func NewManager() Manager {
-	return &manager{
-		Commands: make(map[string]Command),
-	}
+	return (&manager{
+		Aliases:     make(map[string]string),
+		CommandKeys: make(map[string]string),
+		Commands:    make(map[string]Command),
+		GracePeriod: DefaultGracePeriod,
+		IO:          NewIO(),
+		Middlewares: make([]Middleware, 0),
+		Options:     make(map[string]Option),
+	}).initOptions()
 }
 
 // /////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////
 
-func (o *manager) AddCommand(c Command) error      { return o.addCommand(c) }
-func (o *manager) GetCommand(key string) Command   { return o.getCommand(key) }
-func (o *manager) GetCommands() map[string]Command { return o.Commands }
-func (o *manager) GetDescription() string          { return o.Description }
-func (o *manager) Run(a Arguments) error           { return o.run(a) }
-func (o *manager) RunTerminal() error              { return o.runTerminal() }
-func (o *manager) SetDescription(s string) Manager { o.Description = s; return o }
+func (o *manager) AddAlias(alias, path string) error { return o.addAlias(alias, path) }
+func (o *manager) AddCommand(c Command) error        { return o.addCommand(c) }
+func (o *manager) AddOption(opts ...Option) error    { return o.addOption(opts) }
+func (o *manager) BindStruct(v interface{}) error    { return o.bindStruct(v) }
+func (o *manager) Execute(ctx context.Context, x *IO, ss ...string) *Result {
+	return o.execute(ctx, x, ss)
+}
+func (o *manager) GetCommand(key string) Command          { return o.getCommand(key) }
+func (o *manager) GetCommands() map[string]Command        { return o.Commands }
+func (o *manager) GetConfig() (Config, error)             { return o.getConfig() }
+func (o *manager) GetDescription() string                 { return o.Description }
+func (o *manager) GetGracePeriod() time.Duration          { return o.GracePeriod }
+func (o *manager) GetIO() *IO                             { return o.IO }
+func (o *manager) GetInheritedOptions(c Command) []Option { return o.inherited(c) }
+func (o *manager) GetOptions() map[string]Option          { return o.Options }
+func (o *manager) Run(a Arguments) error                  { return o.run(context.Background(), a) }
+func (o *manager) RunContext(ctx context.Context, a Arguments) error {
+	return o.run(ctx, a)
+}
+func (o *manager) RunTerminal() error                     { return o.runTerminal() }
+func (o *manager) SetAbbreviation(b bool) Manager         { o.Abbreviation = b; return o }
+func (o *manager) SetConfigFile(path string) Manager      { o.setConfigFile(path); return o }
+func (o *manager) SetDescription(s string) Manager        { o.Description = s; return o }
+func (o *manager) SetGracePeriod(d time.Duration) Manager { o.GracePeriod = d; return o }
+func (o *manager) SetIO(x *IO) Manager                    { o.IO = x; return o }
+func (o *manager) SetOnError(h ErrorHandler) Manager      { o.OnError = h; return o }
+func (o *manager) SetPostRun(h CommandHandler) Manager    { o.PostRun = h; return o }
+func (o *manager) SetPreRun(h CommandHandler) Manager     { o.PreRun = h; return o }
+func (o *manager) Use(ms ...Middleware) Manager           { o.use(ms); return o }
 
 // /////////////////////////////////////////////////////////////
 // Access and constructor
 // /////////////////////////////////////////////////////////////
 
+// Add hidden alias
+// of command path, such as kv:download for consul kv download.
+// Alias is not listed in help and completion.
+func (o *manager) addAlias(alias, path string) error {
+	if strings.TrimSpace(alias) == "" || len(strings.Fields(alias)) != 1 {
+		return fmt.Errorf("alias must be one word: %s", alias)
+	}
+	if _, ok := o.CommandKeys[alias]; ok {
+		return fmt.Errorf("command exists in manager: %s", alias)
+	}
+	if o.getCommand(path) == nil {
+		return fmt.Errorf("command not registered in manager: %s", path)
+	}
+	o.Aliases[alias] = strings.Join(strings.Fields(path), " ")
+	return nil
+}
+
 func (o *manager) addCommand(c Command) error {
 	if c == nil {
 		return nil
@@ -64,72 +157,688 @@ func (o *manager) addCommand(c Command) error {
 	}
 
 	// Add twice.
-	if _, ok := o.Commands[c.GetName()]; ok {
-		return fmt.Errorf("command exists in manager: %s", c.GetName())
+	for _, k := range append([]string{c.GetName()}, c.GetAliases()...) {
+		_, alias := o.Aliases[k]
+		if _, ok := o.CommandKeys[k]; ok || alias {
+			return fmt.Errorf("command exists in manager: %s", k)
+		}
 	}
 
 	// Set mapper.
 	o.Commands[c.GetName()] = c
+
+	// Full name and alias mapper.
+	o.CommandKeys[c.GetName()] = c.GetName()
+	for _, k := range c.GetAliases() {
+		o.CommandKeys[k] = c.GetName()
+	}
+	return nil
+}
+
+// Add global options
+// accepted by every command.
+func (o *manager) addOption(opts []Option) error {
+	for _, opt := range opts {
+		if opt != nil {
+			o.Options[opt.GetName()] = opt
+		}
+	}
+	return nil
+}
+
+// Build global options
+// with struct tags, values are read in handler by populating a
+// new struct with Arguments.Populate.
+func (o *manager) bindStruct(v interface{}) error {
+	b, err := NewBinder(v)
+	if err != nil {
+		return err
+	}
+	return o.addOption(b.GetOptions())
+}
+
+// Call command
+// in middlewares and hooks of manager, they are outside of
+// middlewares and hooks of command.
+func (o *manager) call(ctx context.Context, c Command, a Arguments) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoverError(c.GetName(), r)
+		}
+	}()
+
+	return chainHandler(func(m Manager, a Arguments) error {
+		return c.RunContext(ctx, m, a)
+	}, o.Middlewares, o.PreRun, o.PostRun, o.OnError)(o, a)
+}
+
+// Names and aliases
+// of visible commands in manager or command group.
+func (o *manager) commandKeys(c Command) []string {
+	var (
+		cs   = o.Commands
+		list = make([]string, 0)
+	)
+
+	if c != nil {
+		cs = c.GetCommands()
+	}
+
+	for _, x := range cs {
+		if !x.GetHidden() {
+			list = append(list, x.GetName())
+			list = append(list, x.GetAliases()...)
+		}
+	}
+
+	sort.Strings(list)
+	return list
+}
+
+// Execute
+// command with arguments like os.Args and standard streams, error
+// message is written to error stream of x, exit code of result is
+// code of typed error.
+//
+//   var out, err bytes.Buffer
+//   res := mng.Execute(ctx, &managers.IO{Out: &out, Err: &err}, "demo", "help")
+func (o *manager) execute(ctx context.Context, x *IO, ss []string) (res *Result) {
+	var (
+		a   = NewArguments()
+		err error
+	)
+
+	// Use standard streams
+	// of manager if not specified.
+	if x == nil {
+		x = o.IO
+	}
+
+	// Parse and run.
+	if err = a.Parse(o.hoist(ss)...); err == nil {
+		err = o.run(ctx, a.SetIO(x))
+	}
+
+	// Write
+	// error message.
+	res = NewResult(err)
+	o.report(x, a.GetScript(), err)
+	return
+}
+
+// Assign option
+// with environment variable or config file value if not
+// specified on command line.
+//
+//   flag > env > config file > default
+func (o *manager) fallback(opt Option) error {
+	// Environment variables.
+	for _, k := range opt.GetEnv() {
+		if s, ok := os.LookupEnv(k); ok && s != "" {
+			return WrapError(ErrorKindValidation, opt.AssignFrom(s, ValueSourceEnv))
+		}
+	}
+
+	// Config file.
+	if k := opt.GetConfigKey(); k != "" && o.ConfigFile != "" {
+		cfg, err := o.getConfig()
+		if err != nil {
+			return err
+		}
+		if s, ok := cfg.GetString(k); ok {
+			return WrapError(ErrorKindValidation, opt.AssignFrom(s, ValueSourceConfig))
+		}
+	}
 	return nil
 }
 
+// Load config file
+// on first access.
+func (o *manager) getConfig() (cfg Config, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.Config == nil && o.ConfigFile != "" {
+		if cfg, err = LoadConfig(o.ConfigFile); err == nil {
+			o.Config = cfg
+		}
+	}
+	return o.Config, err
+}
+
+// Arguments
+// of help command for command path, empty path for manager.
+func (o *manager) helpArguments(path string, x *IO, script string) Arguments {
+	return &arguments{
+		HelpSelector: path,
+		IO:           x,
+		Mapper:       make(map[string]string),
+		Options:      make(map[string]Option),
+		Script:       script,
+		Selector:     ArgumentsHelp,
+	}
+}
+
+// Move global options
+// specified before selector to the end, or before terminator,
+// so selector words lead the arguments.
+//
+//   demo --output json consul kv download key
+//   demo consul kv download key --output json
+func (o *manager) hoist(ss []string) []string {
+	var (
+		i     = 1
+		moved = make([]string, 0)
+	)
+
+	for ; i < len(ss); i++ {
+		s := ss[i]
+		if s == ArgumentsTerminator || !ArgumentsRegexOption.MatchString(s) {
+			break
+		}
+		moved = append(moved, o.hoistName(s))
+
+		// Option value
+		// of next word, such as: --output json.
+		if m := ArgumentsRegexOptionName.FindStringSubmatch(s); len(m) == 3 && i+1 < len(ss) {
+			key := m[2]
+			if m[1] == "-" {
+				key = key[len(key)-1:]
+			}
+			if opt := o.option(key); opt != nil && acceptValue(opt, ss[i+1]) {
+				i++
+				moved = append(moved, ss[i])
+			}
+		}
+	}
+
+	// Return
+	// if no option before selector.
+	if len(ss) == 0 || len(moved) == 0 {
+		return ss
+	}
+
+	// Insert
+	// before terminator or at the end.
+	rest := append([]string{}, ss[i:]...)
+	for k, s := range rest {
+		if s == ArgumentsTerminator {
+			return append(append(append([]string{ss[0]}, rest[:k]...), moved...), rest[k:]...)
+		}
+	}
+	return append(append([]string{ss[0]}, rest...), moved...)
+}
+
+// Hoist name
+// of single short global option to long name, such as -o json, so
+// it is not shadowed by short name of command option.
+func (o *manager) hoistName(s string) string {
+	if m := ArgumentsRegexOptionPairs.FindStringSubmatch(s); len(m) == 3 && !strings.HasPrefix(s, "--") && len(m[1]) == 1 {
+		if opt := o.option(m[1]); opt != nil {
+			return fmt.Sprintf("--%s=%s", opt.GetName(), m[2])
+		}
+	}
+	if m := ArgumentsRegexOptionName.FindStringSubmatch(s); len(m) == 3 && m[1] == "-" && len(m[2]) == 1 {
+		if opt := o.option(m[2]); opt != nil {
+			return "--" + opt.GetName()
+		}
+	}
+	return s
+}
+
+// Options
+// inherited by command, persistent options of command and its
+// ancestors then global options of manager, nearest first and
+// options declared by command are excluded.
+func (o *manager) inherited(c Command) []Option {
+	var (
+		keys = make([]string, 0)
+		list = make([]Option, 0)
+		seen = make(map[string]bool)
+	)
+
+	add := func(opts map[string]Option) {
+		keys = keys[:0]
+		for k := range opts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !seen[k] && (c == nil || c.GetOptions()[k] == nil) {
+				seen[k] = true
+				list = append(list, opts[k])
+			}
+		}
+	}
+
+	for x := c; x != nil; x = x.GetParent() {
+		add(x.GetPersistentOptions())
+	}
+	add(o.Options)
+	return list
+}
+
+// Options of group
+// are all available in invocation or not.
+func (o *manager) inheritedGroup(a Arguments, g OptionGroup) bool {
+	for _, name := range g.GetNames() {
+		if a.GetOption(name) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Register
+// built-in global options.
+func (o *manager) initOptions() *manager {
+	o.Options[OutputOption] = NewOption(OutputOption).
+		SetShortName(OutputOptionByte).
+		SetDescription(OutputOptionDesc).
+		SetEnum(OutputFormats...).
+		SetDefault(OutputFormatTable)
+	return o
+}
+
+// Expand selector
+// if first word is alias of command path.
+//
+//   kv:download -> consul kv download
+func (o *manager) expand(key string) string {
+	if fs := strings.Fields(key); len(fs) > 0 {
+		if path, ok := o.Aliases[fs[0]]; ok {
+			return strings.Join(append([]string{path}, fs[1:]...), " ")
+		}
+	}
+	return key
+}
+
+// Expand arguments
+// if first word is alias of command path, tokens are rewritten so
+// leading words of tokens match words of command path on binding.
+//
+//   kv:download app/myapp -> consul kv download app/myapp
+func (o *manager) expandArguments(a Arguments) {
+	if x, ok := a.(*arguments); ok && len(x.Tokens) > 0 && x.Selector != "" {
+		if path, ok := o.Aliases[x.Tokens[0]]; ok {
+			x.Tokens = append(strings.Fields(path), x.Tokens[1:]...)
+			x.Selector = o.expand(x.Selector)
+		}
+	}
+}
+
+// Read command
+// by selector path, such as: consul kv download.
 func (o *manager) getCommand(key string) Command {
-	if c, ok := o.Commands[key]; ok {
+	key = o.expand(key)
+	if c, n := o.lookupCommand(key); n == len(strings.Fields(key)) {
 		return c
 	}
 	return nil
 }
 
-func (o *manager) run(a Arguments) error {
+// Lookup command
+// with the longest matched selector path and return the
+// count of matched words.
+func (o *manager) lookupCommand(key string) (c Command, n int) {
+	for _, s := range strings.Fields(key) {
+		var x Command
+
+		if c == nil {
+			// Top level command.
+			if k, exists := o.CommandKeys[s]; exists {
+				x = o.Commands[k]
+			}
+		} else {
+			// Child of command group.
+			x = c.GetCommand(s)
+		}
+
+		// Use unique prefix
+		// if abbreviation enabled.
+		if x == nil && o.Abbreviation {
+			if k, ok := Abbreviate(s, o.commandKeys(c)); ok {
+				x = o.lookupChild(c, k)
+			}
+		}
+
+		// Stop
+		// if path not matched.
+		if x == nil {
+			break
+		}
+
+		c = x
+		n++
+	}
+	return
+}
+
+func (o *manager) lookupChild(c Command, key string) Command {
+	if c != nil {
+		return c.GetCommand(key)
+	}
+	if k, exists := o.CommandKeys[key]; exists {
+		return o.Commands[k]
+	}
+	return nil
+}
+
+// Lookup option
+// of invocation, unique prefix of full name is accepted if
+// abbreviation enabled.
+func (o *manager) lookupOption(a Arguments, key string) Option {
+	if opt := a.GetOption(key); opt != nil || !o.Abbreviation || len(key) < 2 {
+		return opt
+	}
+
+	// Full names.
+	names := make([]string, 0)
+	for k := range a.GetOptions() {
+		names = append(names, k)
+	}
+
+	if k, ok := Abbreviate(key, names); ok {
+		return a.GetOption(k)
+	}
+	return nil
+}
+
+// Global option
+// of manager by full name or short name.
+func (o *manager) option(key string) Option {
+	for _, opt := range o.Options {
+		if opt.GetName() == key || opt.GetShortName() == key {
+			return opt
+		}
+	}
+	return nil
+}
+
+// Names of command options
+// and inherited options with dash prefix.
+func (o *manager) optionKeys(a Arguments) []string {
+	list := make([]string, 0)
+
+	for k, opt := range a.GetOptions() {
+		list = append(list, "--"+k)
+		if s := opt.GetShortName(); s != "" && a.GetOption(s) == opt {
+			list = append(list, "-"+s)
+		}
+	}
+
+	sort.Strings(list)
+	return list
+}
+
+// Run command
+// with arguments, option values are assigned to arguments of the
+// invocation rather than shared command options.
+func (o *manager) run(ctx context.Context, a Arguments) error {
+	o.expandArguments(a)
+
 	var (
 		cmd      Command
-		exists   bool
+		n        int
 		selector = a.GetSelector()
 	)
 
+	// Use standard streams
+	// of manager if not specified.
+	if a.GetIO() == nil {
+		a.SetIO(o.IO)
+	}
+
 	// Use default command
 	// if selected is empty.
 	if selector == "" {
 		selector = ArgumentsHelp
 	}
+	selector = o.expand(selector)
+
+	// Read command from mapper, remaining
+	// words are positional arguments.
+	if cmd, n = o.lookupCommand(selector); cmd != nil && (n == len(strings.Fields(selector)) || !cmd.IsGroup()) {
+		// Render group help
+		// if command group has no handler.
+		if cmd.IsGroup() && cmd.GetHandler() == nil && cmd.GetContextHandler() == nil {
+			if hc := o.getCommand(ArgumentsHelp); hc != nil {
+				return o.call(ctx, hc, o.helpArguments(cmd.GetPath(), a.GetIO(), a.GetScript()))
+			}
+		}
+
+		// Return error
+		// if bind arguments failed.
+		if err := a.Bind(cmd, o.inherited(cmd)...); err != nil {
+			return usagePath(cmd, err)
+		}
 
-	// Read command from mapper.
-	if cmd, exists = o.Commands[selector]; exists {
 		// Return error
 		// if arguments option not registered in command.
 		for ak, av := range a.GetMapper() {
-			if co := cmd.GetOption(ak); co != nil {
-				if err := co.Assign(av); err != nil {
-					return err
+			if co := o.lookupOption(a, ak); co != nil {
+				vs := a.GetValues(ak)
+				if len(vs) == 0 {
+					vs = []string{av}
+				}
+				for _, v := range vs {
+					if err := co.Assign(v); err != nil {
+						return WrapError(ErrorKindValidation, err)
+					}
 				}
 				continue
 			}
-			return fmt.Errorf("option not recognized: %s", ak)
+
+			// Return error
+			// with similar option names.
+			if len(ak) == 1 {
+				return usagePath(cmd, suggestError(Suggest("-"+ak, o.optionKeys(a)), "option not recognized: %s", ak))
+			}
+			return usagePath(cmd, suggestError(Suggest("--"+ak, o.optionKeys(a)), "option not recognized: %s", ak))
 		}
 
 		// Return error
-		// if command option validate failed.
-		for _, cv := range cmd.GetOptions() {
-			if err := cv.Validate(); err != nil {
+		// if fallback values assign failed.
+		for _, cv := range a.GetOptions() {
+			if !cv.Assigned() {
+				if err := o.fallback(cv); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Return error
+		// if command option or option group validate failed.
+		if err := o.validate(cmd, a); err != nil {
+			return err
+		}
+
+		// Global output format
+		// if not declared by command.
+		if opt := a.GetOption(OutputOption); opt != nil && opt.Assigned() && cmd.GetOption(OutputOption) == nil {
+			s, err := opt.ToString()
+			if err == nil {
+				err = a.GetOutput().SetFormat(s)
+			}
+			if err != nil {
 				return err
 			}
 		}
 
-		// Run command.
-		return cmd.Run(o, a)
+		// Run command, usage error
+		// of handler is reported with usage of command.
+		return usagePath(cmd, o.call(ctx, cmd, a))
 	}
 
 	// Return error
-	// if command not registered.
-	return fmt.Errorf("command not registered in manager: %s", selector)
+	// with similar command names of matched level.
+	var (
+		list   = make([]string, 0)
+		prefix string
+	)
+	if cmd != nil {
+		prefix = cmd.GetPath() + " "
+	}
+	if fs := strings.Fields(selector); n < len(fs) {
+		for _, k := range Suggest(fs[n], o.commandKeys(cmd)) {
+			list = append(list, prefix+k)
+		}
+	}
+	return usagePath(cmd, suggestError(list, "command not registered in manager: %s", selector))
+}
+
+// Report error
+// to error stream with hint, usage of command is printed after
+// message if usage error returned.
+//
+//   option not recognized: adr
+//
+//   Did you mean this?
+//       --addr
+func (o *manager) report(x *IO, script string, err error) {
+	if err == nil || x.Err == nil {
+		return
+	}
+
+	_, _ = fmt.Fprintln(x.Err, err.Error())
+	if hint := ErrorHint(err); hint != "" {
+		_, _ = fmt.Fprintf(x.Err, "\n%s\n", hint)
+	}
+
+	// Usage of command
+	// rendered by help command.
+	var e *typedError
+	if errors.As(err, &e) && e.Kind == ErrorKindUsage {
+		if hc := o.getCommand(ArgumentsHelp); hc != nil {
+			_ = hc.RunContext(context.Background(), o, o.helpArguments(e.Path, &IO{In: x.In, Out: x.Err, Err: x.Err}, script))
+		}
+	}
 }
 
+// Run command
+// with os arguments, error is reported to error stream and process
+// exit with code of error.
 func (o *manager) runTerminal() error {
 	a := NewArguments()
+	if err := o.terminal(a); err != nil {
+		o.report(o.IO, a.GetScript(), err)
+		os.Exit(ErrorCode(err))
+	}
+	return nil
+}
+
+// Run command with os arguments, context is cancelled when
+// SIGINT or SIGTERM received, and process exit with ExitCodeSignal
+// if command not return in grace period.
+func (o *manager) terminal(a Arguments) error {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan error, 1)
+		sig         = make(chan os.Signal, 1)
+	)
 
-	if err := a.Parse(os.Args...); err != nil {
+	defer cancel()
+
+	if err := a.Parse(o.hoist(os.Args)...); err != nil {
 		return err
 	}
+	a.SetIO(o.IO)
+
+	// Listen signals.
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
 
-	return o.run(a)
+	// Run command
+	// in goroutine.
+	go func() {
+		done <- o.run(ctx, a)
+	}()
+
+	// Return
+	// if command completed.
+	var s os.Signal
+	select {
+	case err := <-done:
+		return err
+	case s = <-sig:
+		cancel()
+		_, _ = fmt.Fprintf(o.IO.Err, "%v signal received, waiting for command stop\n", s)
+	}
+
+	// Wait command stop
+	// in grace period, or force exit.
+	select {
+	case err := <-done:
+		// Interrupted, error returned
+		// by handler after cancel, such as ctx.Err(), is wrapped.
+		if err == nil || errors.Is(err, context.Canceled) {
+			return NewError(ErrorKindInterrupted, "command interrupted by signal: %v", s)
+		}
+		return NewError(ErrorKindInterrupted, "command interrupted by signal: %v: %w", s, err)
+	case <-sig:
+	case <-time.After(o.GracePeriod):
+	}
+
+	_, _ = fmt.Fprintf(o.IO.Err, "command not stopped in %v, force exit\n", o.GracePeriod)
+	os.Exit(ExitCodeSignal)
+	return nil
+}
+
+func (o *manager) setConfigFile(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.Config = nil
+	o.ConfigFile = path
+}
+
+// Validate
+// options and option groups of invocation, all failures are
+// reported with a combined error.
+//
+//   option is required: addr; options are mutually exclusive: --token, --token-file
+func (o *manager) validate(c Command, a Arguments) error {
+	var (
+		keys = make([]string, 0)
+		list = make([]string, 0)
+	)
+
+	// Options
+	// in name order.
+	for k := range a.GetOptions() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := a.GetOptions()[k].Validate(); err != nil {
+			list = append(list, err.Error())
+		}
+	}
+
+	// Option groups
+	// of command and ancestors, groups of ancestors are validated
+	// if all options inherited.
+	for x := c; x != nil; x = x.GetParent() {
+		for _, g := range x.GetOptionGroups() {
+			if x != c && !o.inheritedGroup(a, g) {
+				continue
+			}
+			if err := g.Validate(a); err != nil {
+				list = append(list, err.Error())
+			}
+		}
+	}
+
+	if len(list) > 0 {
+		return NewValidationError("%s", strings.Join(list, "; "))
+	}
+	return nil
+}
+
+func (o *manager) use(ms []Middleware) {
+	for _, m := range ms {
+		if m != nil {
+			o.Middlewares = append(o.Middlewares, m)
+		}
+	}
 }
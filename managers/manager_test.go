@@ -0,0 +1,176 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testParams struct {
+	Count int               `console:"count,c" desc:"Count" default:"1"`
+	Meta  map[string]string `console:"meta" desc:"Meta" default:"env=dev"`
+	Name  string            `console:"name,n" desc:"Name" default:"demo"`
+	Tag   []string          `console:"tag,t" desc:"Tag" default:"x,y"`
+}
+
+// Manager
+// with command which prints params and mutates slice and map.
+func testManager(t *testing.T) Manager {
+	t.Helper()
+
+	m := NewManager()
+	c := NewCommand("echo")
+	if err := c.BindStruct(&testParams{}); err != nil {
+		t.Fatalf("bind struct failed: %v", err)
+	}
+	c.SetHandler(func(m Manager, a Arguments) error {
+		p := &testParams{}
+		if err := a.Populate(p); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(a.GetIO().Out, "%d %s %s %s", p.Count, p.Name, strings.Join(p.Tag, ","), p.Meta["env"])
+
+		// Mutate
+		// values, must not affect later runs.
+		if len(p.Tag) > 0 {
+			p.Tag[0] = "MUT"
+		}
+		p.Meta["env"] = "MUT"
+		return err
+	})
+	if err := m.AddCommand(c); err != nil {
+		t.Fatalf("add command failed: %v", err)
+	}
+	return m
+}
+
+func testExecute(m Manager, ss ...string) (string, *Result) {
+	var out, e bytes.Buffer
+	res := m.Execute(context.Background(), &IO{Out: &out, Err: &e}, append([]string{"demo", "echo"}, ss...)...)
+	return out.String(), res
+}
+
+func TestManagerExecuteRepeated(t *testing.T) {
+	m := testManager(t)
+
+	for i := 0; i < 3; i++ {
+		if s, res := testExecute(m); res.Err != nil || s != "1 demo x,y dev" {
+			t.Fatalf("run %d: output: %q, error: %v", i, s, res.Err)
+		}
+		if s, res := testExecute(m, "-c", "2", "--name=app", "-t", "a", "-t", "b", "--meta", "env=prod"); res.Err != nil || s != "2 app a,b prod" {
+			t.Fatalf("run %d: output: %q, error: %v", i, s, res.Err)
+		}
+	}
+
+	// Defaults
+	// of registered options not changed.
+	opt := m.GetCommand("echo").GetOption("tag")
+	if v, _ := opt.ToStringSlice(); strings.Join(v, ",") != "x,y" {
+		t.Errorf("tag default changed: %v", v)
+	}
+	if opt.Assigned() {
+		t.Errorf("registered option assigned by run")
+	}
+}
+
+func TestManagerExecuteParallel(t *testing.T) {
+	var (
+		m  = testManager(t)
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("app%d", i)
+			expect := fmt.Sprintf("%d %s %s,z dev", i, name, name)
+
+			s, res := testExecute(m, fmt.Sprintf("--count=%d", i), "-n", name, "-t", name, "-t", "z")
+			if res.Err != nil || s != expect {
+				t.Errorf("output: %q, expect: %q, error: %v", s, expect, res.Err)
+			}
+			if s, res = testExecute(m); res.Err != nil || s != "1 demo x,y dev" {
+				t.Errorf("output: %q, error: %v", s, res.Err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestManagerAlias(t *testing.T) {
+	m := testManager(t)
+
+	if err := m.AddAlias("old:echo", "echo"); err != nil {
+		t.Fatalf("add alias failed: %v", err)
+	}
+	if err := m.AddAlias("old:none", "none"); err == nil {
+		t.Errorf("alias of unregistered command added")
+	}
+	if m.GetCommand("old:echo") != m.GetCommand("echo") {
+		t.Errorf("alias not resolved")
+	}
+
+	var out bytes.Buffer
+	res := m.Execute(context.Background(), &IO{Out: &out, Err: &bytes.Buffer{}}, "demo", "old:echo", "-n", "app")
+	if res.Err != nil || out.String() != "1 app x,y dev" {
+		t.Errorf("output: %q, error: %v", out.String(), res.Err)
+	}
+}
+
+func TestManagerAliasPositional(t *testing.T) {
+	m := NewManager()
+	g := NewCommand("group")
+	c := NewCommand("copy")
+	if err := c.AddPositional(
+		NewPositional("from").SetMode(ModeRequired),
+		NewPositional("to"),
+	); err != nil {
+		t.Fatalf("add positional failed: %v", err)
+	}
+	c.SetHandler(func(m Manager, a Arguments) error {
+		_, err := fmt.Fprintf(a.GetIO().Out, "%s %s", a.GetPositional("from"), a.GetPositional("to"))
+		return err
+	})
+	if err := g.AddCommand(c); err != nil {
+		t.Fatalf("add command failed: %v", err)
+	}
+	if err := m.AddCommand(g); err != nil {
+		t.Fatalf("add command failed: %v", err)
+	}
+	if err := m.AddAlias("group:copy", "group copy"); err != nil {
+		t.Fatalf("add alias failed: %v", err)
+	}
+
+	for _, x := range []struct {
+		Args   []string
+		Expect string
+		Failed bool
+	}{
+		{Args: []string{"group", "copy", "a", "b"}, Expect: "a b"},
+		{Args: []string{"group:copy", "a", "b"}, Expect: "a b"},
+		{Args: []string{"group:copy", "a"}, Expect: "a "},
+		{Args: []string{"group:copy", "a", "--", "-b"}, Expect: "a -b"},
+		{Args: []string{"group", "copy", "a", "b", "c"}, Failed: true},
+		{Args: []string{"group:copy", "a", "b", "c"}, Failed: true},
+	} {
+		var out bytes.Buffer
+		res := m.Execute(context.Background(), &IO{Out: &out, Err: &bytes.Buffer{}}, append([]string{"demo"}, x.Args...)...)
+		if x.Failed {
+			if res.Err == nil {
+				t.Errorf("%v: error expected, output: %q", x.Args, out.String())
+			}
+			continue
+		}
+		if res.Err != nil || out.String() != x.Expect {
+			t.Errorf("%v: output: %q, expect: %q, error: %v", x.Args, out.String(), x.Expect, res.Err)
+		}
+	}
+}
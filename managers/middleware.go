@@ -0,0 +1,86 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+type (
+	// ErrorHandler
+	// callable hook on command error, return nil to ignore error or
+	// another error to replace it.
+	ErrorHandler func(manager Manager, arguments Arguments, err error) error
+
+	// Middleware
+	// wrap handler of command, the first used middleware is the
+	// outermost one.
+	//
+	//   m.Use(func(next managers.CommandHandler) managers.CommandHandler {
+	//       return func(m managers.Manager, a managers.Arguments) error {
+	//           t := time.Now()
+	//           err := next(m, a)
+	//           fmt.Fprintf(a.GetIO().Err, "%s completed in %v\n", a.GetSelector(), time.Since(t))
+	//           return err
+	//       }
+	//   })
+	Middleware func(next CommandHandler) CommandHandler
+)
+
+// Chain
+// build handler with middlewares and hooks, hooks are called inside
+// middlewares in order: pre run, handler, post run. Error handler is
+// called for error of whole chain.
+func chainHandler(h CommandHandler, ms []Middleware, pre, post CommandHandler, onError ErrorHandler) CommandHandler {
+	next := func(m Manager, a Arguments) error {
+		if pre != nil {
+			if err := pre(m, a); err != nil {
+				return err
+			}
+		}
+		if err := h(m, a); err != nil {
+			return err
+		}
+		if post != nil {
+			return post(m, a)
+		}
+		return nil
+	}
+
+	for i := len(ms) - 1; i >= 0; i-- {
+		if ms[i] != nil {
+			next = ms[i](next)
+		}
+	}
+
+	if onError == nil {
+		return next
+	}
+	return func(m Manager, a Arguments) error {
+		if err := next(m, a); err != nil {
+			return onError(m, a, err)
+		}
+		return nil
+	}
+}
+
+// Recover
+// panic of handler as error with call stack.
+func recoverError(name string, r interface{}) error {
+	es := []string{
+		fmt.Sprintf("command panic on %v: %v", name, r),
+	}
+
+	for i := 0; ; i++ {
+		if _, f, l, g := runtime.Caller(i); g {
+			es = append(es, fmt.Sprintf("%s:%d", strings.TrimSpace(f), l))
+			continue
+		}
+		break
+	}
+
+	return NewError(ErrorKindPanic, "%s", strings.Join(es, "\n"))
+}
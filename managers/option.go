@@ -5,13 +5,17 @@ package managers
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type (
-	Mode      int
-	ValueType int
+	Mode        int
+	ValueSource int
+	ValueType   int
 )
 
 const (
@@ -19,20 +23,58 @@ const (
 	ModeRequired
 )
 
+const (
+	ValueSourceDefault ValueSource = iota
+	ValueSourceFlag
+	ValueSourceEnv
+	ValueSourceConfig
+)
+
 const (
 	ValueTypeString ValueType = iota
 	ValueTypeBoolean
 	ValueTypeFloat
 	ValueTypeInteger
 	ValueTypeNull
+	ValueTypeStringSlice
+	ValueTypeStringMap
+	ValueTypeCount
+	ValueTypeDuration
+	ValueTypeTime
+	ValueTypeSize
+	ValueTypeURL
+	ValueTypeIP
+	ValueTypeCIDR
+	ValueTypeHostPort
+	ValueTypeFile
+	ValueTypeDir
 )
 
 var (
+	ValueSourceText = map[ValueSource]string{
+		ValueSourceConfig:  "config",
+		ValueSourceDefault: "default",
+		ValueSourceEnv:     "env",
+		ValueSourceFlag:    "flag",
+	}
+
 	ValueTypeText = map[ValueType]string{
-		ValueTypeBoolean: "boolean",
-		ValueTypeFloat:   "float",
-		ValueTypeInteger: "integer",
-		ValueTypeString:  "string",
+		ValueTypeBoolean:     "boolean",
+		ValueTypeCIDR:        "cidr",
+		ValueTypeCount:       "count",
+		ValueTypeDir:         "dir",
+		ValueTypeDuration:    "duration",
+		ValueTypeFile:        "file",
+		ValueTypeFloat:       "float",
+		ValueTypeHostPort:    "host:port",
+		ValueTypeIP:          "ip",
+		ValueTypeInteger:     "integer",
+		ValueTypeSize:        "size",
+		ValueTypeString:      "string",
+		ValueTypeStringMap:   "key=value",
+		ValueTypeStringSlice: "string",
+		ValueTypeTime:        "time",
+		ValueTypeURL:         "url",
 	}
 )
 
@@ -40,39 +82,72 @@ type (
 	// Option
 	// operation interface.
 	Option interface {
+		AddValidator(vs ...OptionValidator) Option
 		Assign(s string) error
+		AssignFrom(s string, source ValueSource) error
 		Assigned() bool
+		Clone() Option
+		GetConfigKey() string
+		GetDefault() interface{}
 		GetDescription() string
+		GetEnum() []string
+		GetEnv() []string
 		GetLabel() string
+		GetMode() Mode
 		GetName() string
 		GetShortName() string
+		GetSource() ValueSource
+		GetSummary() string
+		GetValueType() ValueType
+		IsRepeatable() bool
+		SetConfigKey(key string) Option
 		SetDefault(v interface{}) Option
 		SetDescription(ss ...string) Option
+		SetEnum(values ...string) Option
+		SetEnv(names ...string) Option
 		SetMode(m Mode) Option
 		SetShortName(b byte) Option
 		SetValueType(vt ValueType) Option
 		ToBool() (bool, error)
+		ToCIDR() (*net.IPNet, error)
+		ToCount() (int, error)
+		ToDuration() (time.Duration, error)
 		ToFloat() (float64, error)
+		ToHostPort() (string, error)
+		ToIP() (net.IP, error)
 		ToInt() (int64, error)
+		ToPath() (string, error)
+		ToSize() (int64, error)
 		ToString() (string, error)
+		ToStringMap() (map[string]string, error)
+		ToStringSlice() ([]string, error)
+		ToTime() (time.Time, error)
+		ToURL() (*url.URL, error)
 		Validate() error
 	}
 
 	option struct {
+		ConfigKey       string
 		Default         interface{}
 		Descriptions    []string
+		Enum            []string
+		Env             []string
 		Label           string
 		Mode            Mode
 		Name, ShortName string
 		Value           string
 		ValueAssigned   bool
+		Values          []string
+		ValueSource     ValueSource
 		ValueType       ValueType
+		Validators      []OptionValidator
 	}
 )
 
 func NewOption(name string) Option {
 	return (&option{
 		Descriptions: make([]string, 0),
+		Env:          make([]string, 0),
 		Name:         name,
 		Mode:         ModeOptional, ValueType: ValueTypeString,
 	}).initLabel()
@@ -82,43 +157,120 @@ func NewOption(name string) Option {
 // Interface methods
 // /////////////////////////////////////////////////////////////
 
-func (o *option) Assign(s string) error              { return o.assign(s) }
-func (o *option) Assigned() bool                     { return o.ValueAssigned }
-func (o *option) GetDescription() string             { return o.getDescription() }
-func (o *option) GetLabel() string                   { return o.Label }
-func (o *option) GetName() string                    { return o.Name }
-func (o *option) GetShortName() string               { return o.ShortName }
-func (o *option) SetDefault(v interface{}) Option    { o.Default = v; return o }
-func (o *option) SetDescription(ss ...string) Option { o.setDescription(ss...); return o }
-func (o *option) SetMode(m Mode) Option              { o.Mode = m; return o.initLabel() }
-func (o *option) SetShortName(b byte) Option         { o.ShortName = string(b); return o.initLabel() }
-func (o *option) SetValueType(vt ValueType) Option   { o.ValueType = vt; return o.initLabel() }
-func (o *option) ToBool() (bool, error)              { return o.toBool() }
-func (o *option) ToFloat() (float64, error)          { return o.toFloat() }
-func (o *option) ToInt() (int64, error)              { return o.toInt() }
-func (o *option) ToString() (string, error)          { return o.toString() }
-func (o *option) Validate() error                    { return o.validate() }
+func (o *option) AddValidator(vs ...OptionValidator) Option {
+	o.Validators = append(o.Validators, vs...)
+	return o
+}
+func (o *option) Assign(s string) error                         { return o.assign(s, ValueSourceFlag) }
+func (o *option) AssignFrom(s string, source ValueSource) error { return o.assign(s, source) }
+func (o *option) Assigned() bool                                { return o.ValueAssigned }
+func (o *option) Clone() Option                                 { return o.clone() }
+func (o *option) GetConfigKey() string                          { return o.ConfigKey }
+func (o *option) GetDefault() interface{}                       { return copyValue(o.Default) }
+func (o *option) GetDescription() string                        { return o.getDescription() }
+func (o *option) GetEnum() []string                             { return o.Enum }
+func (o *option) GetEnv() []string                              { return o.Env }
+func (o *option) GetLabel() string                              { return o.Label }
+func (o *option) GetMode() Mode                                 { return o.Mode }
+func (o *option) GetName() string                               { return o.Name }
+func (o *option) GetShortName() string                          { return o.ShortName }
+func (o *option) GetSource() ValueSource                        { return o.ValueSource }
+func (o *option) GetSummary() string                            { return strings.Join(o.Descriptions, " ") }
+func (o *option) GetValueType() ValueType                       { return o.ValueType }
+func (o *option) IsRepeatable() bool                            { return o.isRepeatable() }
+func (o *option) SetConfigKey(key string) Option                { o.ConfigKey = key; return o }
+func (o *option) SetDefault(v interface{}) Option               { o.Default = copyValue(v); return o }
+func (o *option) SetDescription(ss ...string) Option            { o.setDescription(ss...); return o }
+func (o *option) SetEnum(values ...string) Option               { o.Enum = values; return o }
+func (o *option) SetEnv(names ...string) Option                 { o.setEnv(names); return o }
+func (o *option) SetMode(m Mode) Option                         { o.Mode = m; return o.initLabel() }
+func (o *option) SetShortName(b byte) Option                    { o.ShortName = string(b); return o.initLabel() }
+func (o *option) SetValueType(vt ValueType) Option              { o.ValueType = vt; return o.initLabel() }
+func (o *option) ToBool() (bool, error)                         { return o.toBool() }
+func (o *option) ToCIDR() (*net.IPNet, error)                   { return o.toCIDR() }
+func (o *option) ToCount() (int, error)                         { return o.toCount() }
+func (o *option) ToDuration() (time.Duration, error)            { return o.toDuration() }
+func (o *option) ToFloat() (float64, error)                     { return o.toFloat() }
+func (o *option) ToHostPort() (string, error)                   { return o.toText(ValueTypeHostPort) }
+func (o *option) ToIP() (net.IP, error)                         { return o.toIP() }
+func (o *option) ToInt() (int64, error)                         { return o.toInt() }
+func (o *option) ToPath() (string, error)                       { return o.toPath() }
+func (o *option) ToSize() (int64, error)                        { return o.toSize() }
+func (o *option) ToString() (string, error)                     { return o.toString() }
+func (o *option) ToStringMap() (map[string]string, error)       { return o.toStringMap() }
+func (o *option) ToStringSlice() ([]string, error)              { return o.toStringSlice() }
+func (o *option) ToTime() (time.Time, error)                    { return o.toTime() }
+func (o *option) ToURL() (*url.URL, error)                      { return o.toURL() }
+func (o *option) Validate() error                               { return o.validate() }
 
 // /////////////////////////////////////////////////////////////
 // Access and constructor
 // /////////////////////////////////////////////////////////////
 
-func (o *option) assign(s string) error {
+// Assign value,
+// repeatable options append values of each assignment.
+//
+//   --tag=a,b --tag c     -> [a b c]
+//   --meta env=prod,team=x -> {env: prod, team: x}
+//   -vvv                  -> 3
+func (o *option) assign(s string, source ValueSource) error {
 	if o.Value = s; o.Value != "" && o.ValueType == ValueTypeNull {
 		return fmt.Errorf("option not accept any value: %s", o.Name)
 	}
 
+	switch o.ValueType {
+	case ValueTypeCount:
+		if s != "" {
+			if _, err := strconv.Atoi(s); err != nil {
+				return fmt.Errorf("option value convert to count failed: %s", o.Name)
+			}
+		}
+		o.Values = append(o.Values, s)
+	case ValueTypeStringMap, ValueTypeStringSlice:
+		for _, x := range strings.Split(s, ",") {
+			if x = strings.TrimSpace(x); x == "" {
+				continue
+			}
+			if o.ValueType == ValueTypeStringMap && strings.Index(x, "=") < 1 {
+				return fmt.Errorf("option value not key=value pair: %s: %s", o.Name, x)
+			}
+			o.Values = append(o.Values, x)
+		}
+	}
+
 	o.ValueAssigned = true
+	o.ValueSource = source
 	return nil
 }
 
+func (o *option) enumContains(s string) bool {
+	for _, v := range o.Enum {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *option) getDescription() string {
-	ss := o.Descriptions
+	ss := append([]string{}, o.Descriptions...)
 
+	if len(o.Enum) > 0 {
+		ss = append(ss, fmt.Sprintf("(accept: %s)", strings.Join(o.Enum, ", ")))
+	}
 	if o.Default != nil {
 		ss = append(ss, fmt.Sprintf("(default: %v)", o.Default))
 	}
 
+	// Value sources
+	// except command line.
+	if len(o.Env) > 0 {
+		ss = append(ss, fmt.Sprintf("(env: %s)", strings.Join(o.Env, ", ")))
+	}
+	if o.ConfigKey != "" {
+		ss = append(ss, fmt.Sprintf("(config: %s)", o.ConfigKey))
+	}
+
 	return strings.Join(ss, " ")
 }
 
@@ -136,7 +288,7 @@ func (o *option) initLabel() *option {
 	o.Label = fmt.Sprintf("%s--%s", o.Label, o.Name)
 
 	// Option type.
-	if o.ValueType != ValueTypeNull {
+	if o.ValueType != ValueTypeNull && o.ValueType != ValueTypeCount {
 		if s, ok := ValueTypeText[o.ValueType]; ok {
 			if o.Mode == ModeOptional {
 				o.Label = fmt.Sprintf("%s[=%s]", o.Label, s)
@@ -146,9 +298,44 @@ func (o *option) initLabel() *option {
 		}
 	}
 
+	// Repeatable
+	// option can be specified multiple times.
+	if o.isRepeatable() {
+		o.Label += "..."
+	}
+
 	return o
 }
 
+// Copy definition
+// without assigned value, used as per invocation value.
+func (o *option) clone() *option {
+	x := *o
+	x.Default = copyValue(o.Default)
+	x.Descriptions = append([]string{}, o.Descriptions...)
+	x.Env = append([]string{}, o.Env...)
+	x.Values = nil
+	x.reset()
+	return &x
+}
+
+// Clear value
+// assigned by previous run.
+func (o *option) reset() {
+	o.Value = ""
+	o.ValueAssigned = false
+	o.Values = nil
+	o.ValueSource = ValueSourceDefault
+}
+
+func (o *option) isRepeatable() bool {
+	switch o.ValueType {
+	case ValueTypeCount, ValueTypeStringMap, ValueTypeStringSlice:
+		return true
+	}
+	return false
+}
+
 func (o *option) setDescription(ss ...string) {
 	ds := make([]string, 0)
 	for _, s := range ss {
@@ -159,6 +346,16 @@ func (o *option) setDescription(ss ...string) {
 	o.Descriptions = ds
 }
 
+func (o *option) setEnv(names []string) {
+	ns := make([]string, 0)
+	for _, s := range names {
+		if s = strings.TrimSpace(s); s != "" {
+			ns = append(ns, s)
+		}
+	}
+	o.Env = ns
+}
+
 func (o *option) toBool() (bool, error) {
 	if o.ValueType != ValueTypeBoolean {
 		return false, fmt.Errorf("option type not matched on boolean: %s", o.Name)
@@ -173,6 +370,12 @@ func (o *option) toBool() (bool, error) {
 		return false, fmt.Errorf("option value convert to boolean failed: %s", o.Name)
 	}
 
+	// Return true
+	// if specified without value, such as: --override.
+	if o.ValueAssigned {
+		return true, nil
+	}
+
 	// Return
 	// default value.
 	if o.Default != nil {
@@ -187,6 +390,61 @@ func (o *option) toBool() (bool, error) {
 	return false, nil
 }
 
+func (o *option) toCIDR() (*net.IPNet, error) {
+	v, err := o.toValue(ValueTypeCIDR)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if x, ok := v.(*net.IPNet); ok {
+		return x, nil
+	}
+	return nil, fmt.Errorf("option value convert to cidr failed: %s", o.Name)
+}
+
+func (o *option) toCount() (int, error) {
+	if o.ValueType != ValueTypeCount {
+		return 0, fmt.Errorf("option type not matched on count: %s", o.Name)
+	}
+
+	// Return
+	// user value, each empty value counts one.
+	if o.ValueAssigned {
+		n := 0
+		for _, s := range o.Values {
+			if s == "" {
+				n++
+			} else if v, err := strconv.Atoi(s); err == nil {
+				n += v
+			}
+		}
+		return n, nil
+	}
+
+	// Return
+	// default value.
+	if o.Default != nil {
+		if v, ok := o.Default.(int64); ok {
+			return int(v), nil
+		}
+		return 0, fmt.Errorf("option value convert to count failed: %s", o.Name)
+	}
+
+	// Return
+	// system value.
+	return 0, nil
+}
+
+func (o *option) toDuration() (time.Duration, error) {
+	v, err := o.toValue(ValueTypeDuration)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	if x, ok := v.(time.Duration); ok {
+		return x, nil
+	}
+	return 0, fmt.Errorf("option value convert to duration failed: %s", o.Name)
+}
+
 func (o *option) toFloat() (float64, error) {
 	if o.ValueType != ValueTypeFloat {
 		return 0, fmt.Errorf("option type not matched on float: %s", o.Name)
@@ -215,6 +473,17 @@ func (o *option) toFloat() (float64, error) {
 	return 0, nil
 }
 
+func (o *option) toIP() (net.IP, error) {
+	v, err := o.toValue(ValueTypeIP)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if x, ok := v.(net.IP); ok {
+		return x, nil
+	}
+	return nil, fmt.Errorf("option value convert to ip failed: %s", o.Name)
+}
+
 func (o *option) toInt() (int64, error) {
 	if o.ValueType != ValueTypeInteger {
 		return 0, fmt.Errorf("option type not matched on integer: %s", o.Name)
@@ -268,9 +537,198 @@ func (o *option) toString() (string, error) {
 	return "", nil
 }
 
+func (o *option) toPath() (string, error) {
+	if o.ValueType == ValueTypeDir {
+		return o.toText(ValueTypeDir)
+	}
+	return o.toText(ValueTypeFile)
+}
+
+func (o *option) toSize() (int64, error) {
+	v, err := o.toValue(ValueTypeSize)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	if x, ok := v.(int64); ok {
+		return x, nil
+	}
+	return 0, fmt.Errorf("option value convert to size failed: %s", o.Name)
+}
+
+func (o *option) toStringMap() (map[string]string, error) {
+	if o.ValueType != ValueTypeStringMap {
+		return nil, fmt.Errorf("option type not matched on map: %s", o.Name)
+	}
+
+	// Return
+	// user value, later key overrides.
+	if o.ValueAssigned {
+		res := make(map[string]string)
+		for _, s := range o.Values {
+			kv := strings.SplitN(s, "=", 2)
+			res[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+		return res, nil
+	}
+
+	// Return
+	// default value.
+	if o.Default != nil {
+		if v, ok := o.Default.(map[string]string); ok {
+			return copyValue(v).(map[string]string), nil
+		}
+		return nil, fmt.Errorf("option value convert to map failed: %s", o.Name)
+	}
+
+	// Return
+	// system value.
+	return map[string]string{}, nil
+}
+
+func (o *option) toStringSlice() ([]string, error) {
+	if o.ValueType != ValueTypeStringSlice {
+		return nil, fmt.Errorf("option type not matched on slice: %s", o.Name)
+	}
+
+	// Return
+	// user value.
+	if o.ValueAssigned {
+		return append([]string{}, o.Values...), nil
+	}
+
+	// Return
+	// default value.
+	if o.Default != nil {
+		if v, ok := o.Default.([]string); ok {
+			return append([]string{}, v...), nil
+		}
+		return nil, fmt.Errorf("option value convert to slice failed: %s", o.Name)
+	}
+
+	// Return
+	// system value.
+	return []string{}, nil
+}
+
+func (o *option) toText(vt ValueType) (string, error) {
+	v, err := o.toValue(vt)
+	if err != nil || v == nil {
+		return "", err
+	}
+	if x, ok := v.(string); ok {
+		return x, nil
+	}
+	return "", fmt.Errorf("option value convert to %s failed: %s", ValueTypeText[vt], o.Name)
+}
+
+func (o *option) toTime() (time.Time, error) {
+	v, err := o.toValue(ValueTypeTime)
+	if err != nil || v == nil {
+		return time.Time{}, err
+	}
+	if x, ok := v.(time.Time); ok {
+		return x, nil
+	}
+	return time.Time{}, fmt.Errorf("option value convert to time failed: %s", o.Name)
+}
+
+func (o *option) toURL() (*url.URL, error) {
+	v, err := o.toValue(ValueTypeURL)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if x, ok := v.(*url.URL); ok {
+		return x, nil
+	}
+	return nil, fmt.Errorf("option value convert to url failed: %s", o.Name)
+}
+
+// Typed value
+// of user value or default value, nil returned if neither
+// specified. Default value is used as is unless given as text.
+func (o *option) toValue(vt ValueType) (interface{}, error) {
+	if o.ValueType != vt {
+		return nil, fmt.Errorf("option type not matched on %s: %s", ValueTypeText[vt], o.Name)
+	}
+
+	// Return
+	// user value.
+	if o.Value != "" {
+		v, err := ParseValue(vt, o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("option value convert to %s failed: %s: %v", ValueTypeText[vt], o.Name, err)
+		}
+		return v, nil
+	}
+
+	// Return
+	// default value, paths are not required to exist.
+	if s, ok := o.Default.(string); ok && vt != ValueTypeDir && vt != ValueTypeFile {
+		v, err := ParseValue(vt, s)
+		if err != nil {
+			return nil, fmt.Errorf("option value convert to %s failed: %s: %v", ValueTypeText[vt], o.Name, err)
+		}
+		return v, nil
+	}
+	return o.Default, nil
+}
+
 func (o *option) validate() error {
-	if o.Value == "" && o.Mode == ModeRequired {
-		return fmt.Errorf("option is required: %s", o.Name)
+	var values []string
+
+	// Return error
+	// if required option not specified.
+	if o.isRepeatable() {
+		if len(o.Values) == 0 && o.Mode == ModeRequired {
+			return fmt.Errorf("option is required: %s", o.Name)
+		}
+		values = o.Values
+	} else {
+		if o.Value == "" && o.Mode == ModeRequired {
+			return fmt.Errorf("option is required: %s", o.Name)
+		}
+
+		// Return error
+		// if user value can not parse as value type.
+		if o.Value != "" {
+			if _, err := ParseValue(o.ValueType, o.Value); err != nil {
+				return fmt.Errorf("option value convert to %s failed: %s: %v", ValueTypeText[o.ValueType], o.Name, err)
+			}
+			values = []string{o.Value}
+		}
+	}
+
+	// Range values
+	// and run enum check and validators.
+	for _, s := range values {
+		if s == "" {
+			continue
+		}
+		if len(o.Enum) > 0 && !o.enumContains(s) {
+			return fmt.Errorf("option value not accepted: %s: %s, accept: %s", o.Name, s, strings.Join(o.Enum, ", "))
+		}
+		for _, fn := range o.Validators {
+			if err := fn(o, s); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
+
+// Copy value
+// of slice or map, so default value is not shared between clones
+// and handlers.
+func copyValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []string:
+		return append([]string{}, x...)
+	case map[string]string:
+		res := make(map[string]string)
+		for k, s := range x {
+			res[k] = s
+		}
+		return res
+	}
+	return v
+}
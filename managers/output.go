@@ -4,27 +4,105 @@
 package managers
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
 	"sort"
 	"strings"
 )
 
+const (
+	OutputFormatCsv   = "csv"
+	OutputFormatJson  = "json"
+	OutputFormatTable = "table"
+	OutputFormatYaml  = "yaml"
+
+	OutputOption     = "output"
+	OutputOptionByte = 'o'
+	OutputOptionDesc = "Output format"
+)
+
 var (
 	// Output
 	// manager instance.
 	Output OutputManager
+
+	// OutputFormats
+	// supported output formats.
+	OutputFormats = []string{
+		OutputFormatTable,
+		OutputFormatJson,
+		OutputFormatYaml,
+		OutputFormatCsv,
+	}
 )
 
 type (
 	// OutputManager
 	// manager interface.
 	OutputManager interface {
+		// GetFormat
+		// return current output format.
+		GetFormat() string
+
+		// GetWriter
+		// return current output writer.
+		GetWriter() io.Writer
+
+		// Map
+		// print key/value pairs, such as consul command results.
 		Map(keys map[string]interface{}, desc string)
+
+		// Render
+		// print map, struct, slice of structs or slice of maps.
+		Render(v interface{}, desc string) error
+
+		// SetFormat
+		// change output format, accept: table, json, yaml, csv.
+		SetFormat(format string) error
+
+		// SetWriter
+		// change output writer, default is os.Stdout.
+		SetWriter(w io.Writer) OutputManager
+
+		// Table
+		// print rows with headers.
+		Table(headers []string, rows [][]interface{}, desc string)
 	}
 
-	output struct{}
+	output struct {
+		format string
+		writer io.Writer
+	}
 )
 
+// NewOutput
+// create and return output manager with table format, writer is
+// os.Stdout if nil.
+func NewOutput(w io.Writer) OutputManager {
+	o := (&output{}).init()
+	if w != nil {
+		o.writer = w
+	}
+	return o
+}
+
+// GetFormat
+// return current output format.
+func (o *output) GetFormat() string {
+	return o.format
+}
+
+// GetWriter
+// return current output writer.
+func (o *output) GetWriter() io.Writer {
+	return o.writer
+}
+
 // Map
 // format print.
 func (o *output) Map(keys map[string]interface{}, desc string) {
@@ -34,9 +112,27 @@ func (o *output) Map(keys map[string]interface{}, desc string) {
 		list         = make([]string, 0)
 	)
 
+	// Render
+	// with machine readable format.
+	if o.format != OutputFormatTable {
+		rows := make([][]interface{}, 0)
+		for k, v := range keys {
+			rows = append(rows, []interface{}{k, v})
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i][0].(string) < rows[j][0].(string)
+		})
+		if o.format == OutputFormatCsv {
+			o.Table([]string{"key", "value"}, rows, desc)
+		} else {
+			o.encode(o.normalize(keys))
+		}
+		return
+	}
+
 	// Range
 	// key to list and execute maximum width.
-	for k, _ := range keys {
+	for k := range keys {
 		list = append(list, k)
 
 		// Generate
@@ -66,12 +162,286 @@ func (o *output) Map(keys map[string]interface{}, desc string) {
 	}
 }
 
+// Render
+// print map, struct, slice of structs or slice of maps.
+func (o *output) Render(v interface{}, desc string) error {
+	var (
+		rv = reflect.Indirect(reflect.ValueOf(v))
+	)
+
+	// Key/value pairs.
+	if m, ok := v.(map[string]interface{}); ok {
+		o.Map(m, desc)
+		return nil
+	}
+
+	// Machine readable
+	// format except csv.
+	if o.format == OutputFormatJson || o.format == OutputFormatYaml {
+		o.encode(o.normalize(v))
+		return nil
+	}
+
+	// Table or csv.
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		headers, rows := o.rows(rv)
+		o.Table(headers, rows, desc)
+	case reflect.Struct, reflect.Map:
+		headers, rows := o.rows(reflect.ValueOf([]interface{}{rv.Interface()}))
+		o.Table(headers, rows, desc)
+	default:
+		return fmt.Errorf("output not support type: %T", v)
+	}
+	return nil
+}
+
+// SetFormat
+// change output format.
+func (o *output) SetFormat(format string) error {
+	for _, s := range OutputFormats {
+		if s == format {
+			o.format = format
+			return nil
+		}
+	}
+	return fmt.Errorf("output format not supported: %s", format)
+}
+
+// SetWriter
+// change output writer.
+func (o *output) SetWriter(w io.Writer) OutputManager {
+	o.writer = w
+	return o
+}
+
+// Table
+// print rows with headers.
+//
+//   Description
+//   --------------------------------------------------------------------------------
+//   NAME      ADDRESS       PORT
+//   myapp     172.16.0.1    8080
+func (o *output) Table(headers []string, rows [][]interface{}, desc string) {
+	switch o.format {
+	case OutputFormatCsv:
+		w := csv.NewWriter(o.writer)
+		_ = w.Write(headers)
+		for _, row := range rows {
+			_ = w.Write(o.strings(row))
+		}
+		w.Flush()
+
+	case OutputFormatJson, OutputFormatYaml:
+		list := make([]map[string]interface{}, 0)
+		for _, row := range rows {
+			item := make(map[string]interface{})
+			for i, h := range headers {
+				if i < len(row) {
+					item[h] = row[i]
+				}
+			}
+			list = append(list, item)
+		}
+		o.encode(o.normalize(list))
+
+	default:
+		var (
+			cells  = [][]string{headers}
+			widths = make([]int, len(headers))
+		)
+
+		// Collect cells
+		// and execute column width.
+		for _, row := range rows {
+			cells = append(cells, o.strings(row))
+		}
+		for _, row := range cells {
+			for i, s := range row {
+				if i < len(widths) && widths[i] < len(s) {
+					widths[i] = len(s)
+				}
+			}
+		}
+
+		// Print
+		// description and rows.
+		if desc != "" {
+			o.println(desc)
+			o.println(strings.Repeat("-", 80))
+		}
+		for i, row := range cells {
+			ss := make([]string, 0)
+			for j, s := range row {
+				if j < len(widths) {
+					if i == 0 {
+						s = strings.ToUpper(s)
+					}
+					ss = append(ss, fmt.Sprintf(fmt.Sprintf("%%-%ds", widths[j]), s))
+				}
+			}
+			o.println("%s", strings.TrimRight(strings.Join(ss, "    "), " "))
+		}
+	}
+}
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor methods
+// /////////////////////////////////////////////////////////////
+
+// Encode value
+// as json or yaml format.
+func (o *output) encode(v interface{}) {
+	var (
+		buf []byte
+		err error
+	)
+
+	if o.format == OutputFormatYaml {
+		buf, err = yaml.Marshal(v)
+	} else {
+		buf, err = json.MarshalIndent(v, "", "    ")
+		buf = append(buf, '\n')
+	}
+
+	if err != nil {
+		o.println("%v", err)
+		return
+	}
+
+	_, _ = o.writer.Write(buf)
+}
+
 // Init output instance.
 func (o *output) init() *output {
+	o.format = OutputFormatTable
+	o.writer = os.Stdout
 	return o
 }
 
+// Normalize value
+// as json compatible structure, error is converted as string.
+func (o *output) normalize(v interface{}) (res interface{}) {
+	var (
+		buf []byte
+		err error
+	)
+
+	// Convert error
+	// in key/value pairs.
+	if m, ok := v.(map[string]interface{}); ok {
+		x := make(map[string]interface{})
+		for k, mv := range m {
+			if e, ok := mv.(error); ok {
+				x[k] = e.Error()
+			} else {
+				x[k] = mv
+			}
+		}
+		v = x
+	}
+
+	// Use json tags
+	// for yaml fields.
+	if buf, err = json.Marshal(v); err == nil {
+		if err = json.Unmarshal(buf, &res); err == nil {
+			return
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
 // Print contents.
 func (o *output) println(text string, args ...interface{}) {
-	println(fmt.Sprintf(text, args...))
+	_, _ = fmt.Fprintf(o.writer, "%s\n", fmt.Sprintf(text, args...))
+}
+
+// Rows
+// of slice elements, element is struct or map.
+func (o *output) rows(rv reflect.Value) (headers []string, rows [][]interface{}) {
+	var (
+		index = make(map[string]int)
+	)
+
+	headers = make([]string, 0)
+	rows = make([][]interface{}, 0)
+
+	// Header index.
+	add := func(h string) int {
+		if i, ok := index[h]; ok {
+			return i
+		}
+		index[h] = len(headers)
+		headers = append(headers, h)
+		return index[h]
+	}
+
+	// Range elements.
+	for i := 0; i < rv.Len(); i++ {
+		var (
+			ev  = reflect.Indirect(rv.Index(i))
+			row = make(map[int]interface{})
+		)
+
+		// Unwrap interface.
+		if ev.Kind() == reflect.Interface {
+			ev = reflect.Indirect(ev.Elem())
+		}
+
+		switch ev.Kind() {
+		case reflect.Struct:
+			for j := 0; j < ev.NumField(); j++ {
+				if f := ev.Type().Field(j); f.PkgPath == "" && f.Tag.Get("json") != "-" {
+					row[add(o.fieldName(f))] = ev.Field(j).Interface()
+				}
+			}
+		case reflect.Map:
+			keys := ev.MapKeys()
+			sort.Slice(keys, func(a, b int) bool {
+				return fmt.Sprintf("%v", keys[a]) < fmt.Sprintf("%v", keys[b])
+			})
+			for _, k := range keys {
+				row[add(fmt.Sprintf("%v", k))] = ev.MapIndex(k).Interface()
+			}
+		default:
+			row[add("value")] = ev.Interface()
+		}
+
+		// Collect row.
+		rs := make([]interface{}, len(headers))
+		for j, x := range row {
+			rs[j] = x
+		}
+		rows = append(rows, rs)
+	}
+
+	// Fill short rows.
+	for i, r := range rows {
+		for len(r) < len(headers) {
+			r = append(r, nil)
+		}
+		rows[i] = r
+	}
+	return
+}
+
+// Field name
+// of struct, use json tag if defined.
+func (o *output) fieldName(f reflect.StructField) string {
+	if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" {
+		return tag
+	}
+	return f.Name
+}
+
+// Convert cells
+// as string slice.
+func (o *output) strings(row []interface{}) []string {
+	ss := make([]string, len(row))
+	for i, v := range row {
+		if v != nil {
+			ss[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return ss
 }
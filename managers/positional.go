@@ -0,0 +1,111 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-18
+
+package managers
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Positional
+	// operation interface for positional argument.
+	Positional interface {
+		GetDescription() string
+		GetLabel() string
+		GetMode() Mode
+		GetName() string
+		GetValueType() ValueType
+		IsVariadic() bool
+		SetDescription(ss ...string) Positional
+		SetMode(m Mode) Positional
+		SetValueType(vt ValueType) Positional
+		SetVariadic(b bool) Positional
+		Validate(values []string) error
+	}
+
+	positional struct {
+		Descriptions []string
+		Label        string
+		Mode         Mode
+		Name         string
+		ValueType    ValueType
+		Variadic     bool
+	}
+)
+
+func NewPositional(name string) Positional {
+	return (&positional{
+		Descriptions: make([]string, 0),
+		Name:         name,
+		Mode:         ModeOptional, ValueType: ValueTypeString,
+	}).initLabel()
+}
+
+// /////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////
+
+func (o *positional) GetDescription() string                 { return strings.Join(o.Descriptions, " ") }
+func (o *positional) GetLabel() string                       { return o.Label }
+func (o *positional) GetMode() Mode                          { return o.Mode }
+func (o *positional) GetName() string                        { return o.Name }
+func (o *positional) GetValueType() ValueType                { return o.ValueType }
+func (o *positional) IsVariadic() bool                       { return o.Variadic }
+func (o *positional) SetDescription(ss ...string) Positional { o.setDescription(ss...); return o }
+func (o *positional) SetMode(m Mode) Positional              { o.Mode = m; return o.initLabel() }
+func (o *positional) SetValueType(vt ValueType) Positional   { o.ValueType = vt; return o }
+func (o *positional) SetVariadic(b bool) Positional          { o.Variadic = b; return o.initLabel() }
+func (o *positional) Validate(values []string) error         { return o.validate(values) }
+
+// /////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////
+
+func (o *positional) initLabel() *positional {
+	// Required or optional.
+	if o.Mode == ModeRequired {
+		o.Label = fmt.Sprintf("<%s>", o.Name)
+	} else {
+		o.Label = fmt.Sprintf("[%s]", o.Name)
+	}
+
+	// Accept multiple values.
+	if o.Variadic {
+		o.Label += "..."
+	}
+
+	return o
+}
+
+func (o *positional) setDescription(ss ...string) {
+	ds := make([]string, 0)
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			ds = append(ds, s)
+		}
+	}
+	o.Descriptions = ds
+}
+
+func (o *positional) validate(values []string) (err error) {
+	if len(values) == 0 && o.Mode == ModeRequired {
+		return NewUsageError("argument is required: %s", o.Name)
+	}
+
+	// Null type
+	// not accept any value.
+	if len(values) > 0 && o.ValueType == ValueTypeNull {
+		return NewUsageError("argument not accept any value: %s", o.Name)
+	}
+
+	// Range values
+	// and verify value type.
+	for _, s := range values {
+		if _, err = ParseValue(o.ValueType, s); err != nil {
+			return NewValidationError("argument value convert to %s failed: %s", ValueTypeText[o.ValueType], o.Name)
+		}
+	}
+	return nil
+}
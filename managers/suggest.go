@@ -0,0 +1,134 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-20
+
+package managers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// SuggestDistance
+	// maximum levenshtein distance of suggestions.
+	SuggestDistance = 2
+)
+
+// Abbreviate
+// return the only candidate started with input.
+//
+//   Abbreviate("dow", []string{"download", "upload"}) // download, true
+//   Abbreviate("d", []string{"deregister", "download"}) // "", false
+func Abbreviate(input string, candidates []string) (string, bool) {
+	var (
+		match string
+		n     = 0
+	)
+
+	for _, s := range candidates {
+		// Exact matched.
+		if s == input {
+			return s, true
+		}
+
+		// Prefix matched.
+		if strings.HasPrefix(s, input) && s != match {
+			match = s
+			n++
+		}
+	}
+
+	if n == 1 {
+		return match, true
+	}
+	return "", false
+}
+
+// Levenshtein
+// return edit distance of two strings.
+func Levenshtein(a, b string) int {
+	var (
+		ra, rb = []rune(a), []rune(b)
+		prev   = make([]int, len(rb)+1)
+		curr   = make([]int, len(rb)+1)
+	)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			// Minimum of
+			// deletion, insertion and substitution.
+			curr[j] = prev[j] + 1
+			if x := curr[j-1] + 1; x < curr[j] {
+				curr[j] = x
+			}
+			if x := prev[j-1] + cost; x < curr[j] {
+				curr[j] = x
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Suggest
+// return candidates similar to input, sorted by distance. Short
+// input accept smaller distance, such as: -x only match -X.
+func Suggest(input string, candidates []string) []string {
+	var (
+		distances = make(map[string]int)
+		limit     = (len([]rune(input)) - 1) / 2
+		list      = make([]string, 0)
+	)
+
+	if limit > SuggestDistance {
+		limit = SuggestDistance
+	}
+
+	for _, s := range candidates {
+		if _, ok := distances[s]; ok || s == "" {
+			continue
+		}
+
+		// Similar
+		// or prefix matched.
+		if d := Levenshtein(strings.ToLower(input), strings.ToLower(s)); d <= limit || strings.HasPrefix(s, input) {
+			distances[s] = d
+			list = append(list, s)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if distances[list[i]] == distances[list[j]] {
+			return list[i] < list[j]
+		}
+		return distances[list[i]] < distances[list[j]]
+	})
+	return list
+}
+
+// Return usage error
+// with suggestions as hint.
+func suggestError(list []string, format string, args ...interface{}) error {
+	err := NewUsageError(format, args...)
+
+	if len(list) > 0 {
+		hint := "Did you mean this?"
+		for _, s := range list {
+			hint += fmt.Sprintf("\n    %s", s)
+		}
+		err.SetHint(hint)
+	}
+
+	return err
+}
@@ -0,0 +1,129 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAbbreviate(t *testing.T) {
+	for _, x := range []struct {
+		Input      string
+		Candidates []string
+		Expect     string
+		Ok         bool
+	}{
+		{Input: "dow", Candidates: []string{"download", "upload"}, Expect: "download", Ok: true},
+		{Input: "d", Candidates: []string{"deregister", "download"}},
+		{Input: "down", Candidates: []string{"down", "download"}, Expect: "down", Ok: true},
+		{Input: "dow", Candidates: []string{"download", "download"}, Expect: "download", Ok: true},
+		{Input: "x", Candidates: []string{"download", "upload"}},
+		{Input: "dow", Candidates: nil},
+	} {
+		if s, ok := Abbreviate(x.Input, x.Candidates); s != x.Expect || ok != x.Ok {
+			t.Errorf("abbreviate %q in %v: %q, %v", x.Input, x.Candidates, s, ok)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	for _, x := range []struct {
+		A, B   string
+		Expect int
+	}{
+		{A: "", B: "", Expect: 0},
+		{A: "", B: "abc", Expect: 3},
+		{A: "abc", B: "", Expect: 3},
+		{A: "abc", B: "abc", Expect: 0},
+		{A: "kitten", B: "sitting", Expect: 3},
+		{A: "addr", B: "adr", Expect: 1},
+		{A: "héllo", B: "hello", Expect: 1},
+	} {
+		if n := Levenshtein(x.A, x.B); n != x.Expect {
+			t.Errorf("levenshtein %q, %q: %d, expect: %d", x.A, x.B, n, x.Expect)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	for _, x := range []struct {
+		Input      string
+		Candidates []string
+		Expect     []string
+	}{
+		// Sorted by distance
+		// then by name.
+		{Input: "--adr", Candidates: []string{"--path", "--addr", "--address", "--add"}, Expect: []string{"--add", "--addr"}},
+		{Input: "downlod", Candidates: []string{"upload", "download", "deregister"}, Expect: []string{"download"}},
+		{Input: "--scheem", Candidates: []string{"--scheme", "--schema", "--name"}, Expect: []string{"--schema", "--scheme"}},
+
+		// Short input
+		// accept smaller distance.
+		{Input: "-x", Candidates: []string{"-X", "-y", "-xy"}, Expect: []string{"-X", "-xy"}},
+		{Input: "kv", Candidates: []string{"kb", "kvs", "ab"}, Expect: []string{"kvs"}},
+		{Input: "dwnlad", Candidates: []string{"download"}, Expect: []string{"download"}},
+		{Input: "dwnld", Candidates: []string{"download"}, Expect: []string{}},
+
+		// Prefix matched
+		// beyond distance.
+		{Input: "dow", Candidates: []string{"download", "upload"}, Expect: []string{"download"}},
+
+		// Duplicated
+		// and empty candidates.
+		{Input: "help", Candidates: []string{"helo", "help", "", "help", "hlep"}, Expect: []string{"help", "helo"}},
+	} {
+		if list := Suggest(x.Input, x.Candidates); strings.Join(list, ",") != strings.Join(x.Expect, ",") {
+			t.Errorf("suggest %q in %v: %v, expect: %v", x.Input, x.Candidates, list, x.Expect)
+		}
+	}
+}
+
+func TestManagerAbbreviation(t *testing.T) {
+	type params struct {
+		Force bool   `console:"force" desc:"Force" default:"false"`
+		Level int    `console:"level,l,count" desc:"Level"`
+		Path  string `console:"path" desc:"Path" default:"./"`
+	}
+
+	m := NewManager().SetAbbreviation(true)
+	c := NewCommand("upload")
+	if err := c.BindStruct(&params{}); err != nil {
+		t.Fatalf("bind struct failed: %v", err)
+	}
+	if err := c.AddPositional(NewPositional("name")); err != nil {
+		t.Fatalf("add positional failed: %v", err)
+	}
+	c.SetHandler(func(m Manager, a Arguments) error {
+		p := &params{}
+		if err := a.Populate(p); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(a.GetIO().Out, "%v %d %s %s", p.Force, p.Level, p.Path, a.GetPositional("name"))
+		return err
+	})
+	if err := m.AddCommand(c); err != nil {
+		t.Fatalf("add command failed: %v", err)
+	}
+
+	for _, x := range []struct {
+		Args   []string
+		Expect string
+	}{
+		{Args: []string{"up", "--for", "app"}, Expect: "true 0 ./ app"},
+		{Args: []string{"up", "--force", "true", "app"}, Expect: "true 0 ./ app"},
+		{Args: []string{"up", "--fo=false", "app"}, Expect: "false 0 ./ app"},
+		{Args: []string{"up", "--lev", "app"}, Expect: "false 1 ./ app"},
+		{Args: []string{"up", "--pa", "./config", "app"}, Expect: "false 0 ./config app"},
+	} {
+		var out bytes.Buffer
+		res := m.Execute(context.Background(), &IO{Out: &out, Err: &bytes.Buffer{}}, append([]string{"demo"}, x.Args...)...)
+		if res.Err != nil || out.String() != x.Expect {
+			t.Errorf("%v: output: %q, expect: %q, error: %v", x.Args, out.String(), x.Expect, res.Err)
+		}
+	}
+}
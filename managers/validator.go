@@ -0,0 +1,86 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+type (
+	// OptionValidator
+	// validate each user value of option, values of repeatable
+	// option are validated one by one.
+	//
+	//   opt.AddValidator(managers.ValidateRange(1, 65535))
+	//   opt.AddValidator(func(opt managers.Option, s string) error {
+	//       if strings.HasPrefix(s, "/") {
+	//           return fmt.Errorf("option value must be relative path: %s", opt.GetName())
+	//       }
+	//       return nil
+	//   })
+	OptionValidator func(opt Option, s string) error
+)
+
+// ValidateLength
+// return validator that limit count of characters, zero max
+// means no limit.
+func ValidateLength(min, max int) OptionValidator {
+	return func(opt Option, s string) error {
+		if n := utf8.RuneCountInString(s); n < min || (max > 0 && n > max) {
+			if max > 0 {
+				return fmt.Errorf("option value length must be between %d and %d: %s", min, max, opt.GetName())
+			}
+			return fmt.Errorf("option value length must not be less than %d: %s", min, opt.GetName())
+		}
+		return nil
+	}
+}
+
+// ValidateRange
+// return validator that limit numeric value, size value is
+// compared by bytes, use math.Inf for unlimited side.
+func ValidateRange(min, max float64) OptionValidator {
+	return func(opt Option, s string) error {
+		var (
+			err error
+			n   float64
+		)
+
+		if opt.GetValueType() == ValueTypeSize {
+			var v int64
+			v, err = ParseSize(s)
+			n = float64(v)
+		} else {
+			n, err = strconv.ParseFloat(s, 64)
+		}
+
+		if err != nil {
+			return fmt.Errorf("option value is not a number: %s", opt.GetName())
+		}
+		switch {
+		case n >= min && n <= max:
+			return nil
+		case math.IsInf(min, -1):
+			return fmt.Errorf("option value must not be greater than %v: %s", max, opt.GetName())
+		case math.IsInf(max, 1):
+			return fmt.Errorf("option value must not be less than %v: %s", min, opt.GetName())
+		}
+		return fmt.Errorf("option value must be between %v and %v: %s", min, max, opt.GetName())
+	}
+}
+
+// ValidateRegex
+// return validator that value must match regular expression.
+func ValidateRegex(re *regexp.Regexp) OptionValidator {
+	return func(opt Option, s string) error {
+		if !re.MatchString(s) {
+			return fmt.Errorf("option value not match %s: %s", re.String(), opt.GetName())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	var (
+		num  = NewOption("port").SetValueType(ValueTypeInteger)
+		size = NewOption("limit").SetValueType(ValueTypeSize)
+		text = NewOption("id")
+	)
+
+	for _, x := range []struct {
+		Name      string
+		Option    Option
+		Validator OptionValidator
+		Input     string
+		Failed    bool
+	}{
+		{Name: "range", Option: num, Validator: ValidateRange(1, 65535), Input: "1"},
+		{Name: "range", Option: num, Validator: ValidateRange(1, 65535), Input: "65535"},
+		{Name: "range", Option: num, Validator: ValidateRange(1, 65535), Input: "0", Failed: true},
+		{Name: "range", Option: num, Validator: ValidateRange(1, 65535), Input: "65536", Failed: true},
+		{Name: "range", Option: num, Validator: ValidateRange(1, 65535), Input: "port", Failed: true},
+		{Name: "range min", Option: num, Validator: ValidateRange(1, math.Inf(1)), Input: "99999999"},
+		{Name: "range min", Option: num, Validator: ValidateRange(1, math.Inf(1)), Input: "-1", Failed: true},
+		{Name: "range max", Option: num, Validator: ValidateRange(math.Inf(-1), 10), Input: "-99999999"},
+		{Name: "range max", Option: num, Validator: ValidateRange(math.Inf(-1), 10), Input: "11", Failed: true},
+		{Name: "range size", Option: size, Validator: ValidateRange(0, 1<<20), Input: "1MiB"},
+		{Name: "range size", Option: size, Validator: ValidateRange(0, 1<<20), Input: "1.1MiB", Failed: true},
+		{Name: "range size", Option: size, Validator: ValidateRange(0, 1<<20), Input: "1XB", Failed: true},
+		{Name: "regex", Option: text, Validator: ValidateRegex(regexp.MustCompile(`^[a-z0-9-]+$`)), Input: "myapp-1"},
+		{Name: "regex", Option: text, Validator: ValidateRegex(regexp.MustCompile(`^[a-z0-9-]+$`)), Input: "MyApp", Failed: true},
+		{Name: "length", Option: text, Validator: ValidateLength(2, 4), Input: "ab"},
+		{Name: "length", Option: text, Validator: ValidateLength(2, 4), Input: "日本語字"},
+		{Name: "length", Option: text, Validator: ValidateLength(2, 4), Input: "a", Failed: true},
+		{Name: "length", Option: text, Validator: ValidateLength(2, 4), Input: "abcde", Failed: true},
+		{Name: "length min", Option: text, Validator: ValidateLength(2, 0), Input: "abcdefghij"},
+		{Name: "length min", Option: text, Validator: ValidateLength(2, 0), Input: "a", Failed: true},
+	} {
+		if err := x.Validator(x.Option, x.Input); (err != nil) != x.Failed {
+			t.Errorf("%s %q: error: %v, expect failed: %v", x.Name, x.Input, err, x.Failed)
+		}
+	}
+}
+
+func TestBindingValidators(t *testing.T) {
+	type params struct {
+		Scheme  string   `console:"scheme" desc:"Scheme" default:"http" enum:"http,https"`
+		Port    int      `console:"port" desc:"Port" default:"80" min:"1" max:"65535"`
+		Weight  float64  `console:"weight" desc:"Weight" min:"0.5"`
+		Id      string   `console:"id" desc:"Id" regex:"^[a-z0-9-]+$" length:"2,8"`
+		Tag     []string `console:"tag" desc:"Tag" length:"2"`
+		Verbose int      `console:"verbose,v,count" desc:"Verbose"`
+	}
+
+	m := NewManager()
+	c := NewCommand("run")
+	if err := c.BindStruct(&params{}); err != nil {
+		t.Fatalf("bind struct failed: %v", err)
+	}
+	c.SetHandler(func(m Manager, a Arguments) error {
+		p := &params{}
+		if err := a.Populate(p); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(a.GetIO().Out, "%s %d %v %s %v %d", p.Scheme, p.Port, p.Weight, p.Id, p.Tag, p.Verbose)
+		return err
+	})
+	if err := m.AddCommand(c); err != nil {
+		t.Fatalf("add command failed: %v", err)
+	}
+
+	for _, x := range []struct {
+		Args   []string
+		Expect string
+		Code   int
+	}{
+		{Expect: "http 80 0  [] 0"},
+		{Args: []string{"--scheme=https", "--port=8080", "--weight=1.5", "--id=myapp-1", "--tag=ab", "--tag=cd"}, Expect: "https 8080 1.5 myapp-1 [ab cd] 0"},
+
+		// Enum.
+		{Args: []string{"--scheme=ftp"}, Code: ExitCodeValidation},
+
+		// Min and max.
+		{Args: []string{"--port=0"}, Code: ExitCodeValidation},
+		{Args: []string{"--port=65536"}, Code: ExitCodeValidation},
+		{Args: []string{"--weight=0.4"}, Code: ExitCodeValidation},
+		{Args: []string{"--weight=99999"}, Expect: "http 80 99999  [] 0"},
+
+		// Regex and length.
+		{Args: []string{"--id=MyApp"}, Code: ExitCodeValidation},
+		{Args: []string{"--id=a"}, Code: ExitCodeValidation},
+		{Args: []string{"--id=abcdefghi"}, Code: ExitCodeValidation},
+		{Args: []string{"--tag=ab", "--tag=c"}, Code: ExitCodeValidation},
+
+		// Count flag.
+		{Args: []string{"-v"}, Expect: "http 80 0  [] 1"},
+		{Args: []string{"-vvv"}, Expect: "http 80 0  [] 3"},
+		{Args: []string{"-vv", "-v"}, Expect: "http 80 0  [] 3"},
+		{Args: []string{"--verbose", "--verbose"}, Expect: "http 80 0  [] 2"},
+		{Args: []string{"--verbose=2", "-v"}, Expect: "http 80 0  [] 3"},
+	} {
+		var out bytes.Buffer
+		res := m.Execute(context.Background(), &IO{Out: &out, Err: &bytes.Buffer{}}, append([]string{"demo", "run"}, x.Args...)...)
+		if res.Code != x.Code || (x.Code == ExitCodeSuccess && out.String() != x.Expect) {
+			t.Errorf("%v: code: %d, output: %q, expect: %d %q, error: %v", x.Args, res.Code, out.String(), x.Code, x.Expect, res.Err)
+		}
+	}
+}
+
+func TestBindingTagErrors(t *testing.T) {
+	for name, v := range map[string]interface{}{
+		"count on string": &struct {
+			Level string `console:"level,l,count" desc:"Level"`
+		}{},
+		"min": &struct {
+			Port int `console:"port" desc:"Port" min:"one"`
+		}{},
+		"max": &struct {
+			Port int `console:"port" desc:"Port" max:"many"`
+		}{},
+		"regex": &struct {
+			Id string `console:"id" desc:"Id" regex:"[a-z"`
+		}{},
+		"length": &struct {
+			Id string `console:"id" desc:"Id" length:"1,x"`
+		}{},
+		"count default": &struct {
+			Level int `console:"level,l,count" desc:"Level" default:"high"`
+		}{},
+	} {
+		if err := NewCommand("run").BindStruct(v); err == nil {
+			t.Errorf("%s: bind error expected", name)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ValueRegexSize = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+
+	// ValueSizeUnits
+	// multiplier of size unit in lower case, KB/MB/GB/TB are decimal,
+	// single letter and KiB/MiB/GiB/TiB are binary.
+	ValueSizeUnits = map[string]float64{
+		"":    1,
+		"b":   1,
+		"k":   1 << 10,
+		"kb":  1000,
+		"kib": 1 << 10,
+		"m":   1 << 20,
+		"mb":  1000 * 1000,
+		"mib": 1 << 20,
+		"g":   1 << 30,
+		"gb":  1000 * 1000 * 1000,
+		"gib": 1 << 30,
+		"t":   1 << 40,
+		"tb":  1000 * 1000 * 1000 * 1000,
+		"tib": 1 << 40,
+	}
+)
+
+// ParseSize
+// parse human byte size to bytes, error wraps strconv.ErrRange if
+// bytes overflow int64.
+//
+//   ParseSize("512")    // 512
+//   ParseSize("10MB")   // 10000000
+//   ParseSize("1.5GiB") // 1610612736
+func ParseSize(s string) (int64, error) {
+	m := ValueRegexSize.FindStringSubmatch(strings.TrimSpace(s))
+	if len(m) != 3 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+
+	u, ok := ValueSizeUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit: %s", m[2])
+	}
+
+	// Integer
+	// multiplied without precision loss.
+	if !strings.Contains(m[1], ".") {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || n > math.MaxInt64/int64(u) {
+			return 0, fmt.Errorf("size out of range: %s: %w", s, strconv.ErrRange)
+		}
+		return n * int64(u), nil
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	if n *= u; n >= math.MaxInt64 {
+		return 0, fmt.Errorf("size out of range: %s: %w", s, strconv.ErrRange)
+	}
+	return int64(n), nil
+}
+
+// ParseValue
+// parse text to typed value of value type, string kinds are
+// returned as is.
+//
+//   ParseValue(ValueTypeDuration, "30s")           // time.Duration
+//   ParseValue(ValueTypeHostPort, "127.0.0.1:8500") // string
+func ParseValue(vt ValueType, s string) (interface{}, error) {
+	switch vt {
+	case ValueTypeBoolean:
+		return strconv.ParseBool(s)
+	case ValueTypeCIDR:
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr: %s", s)
+		}
+		return n, nil
+	case ValueTypeDir, ValueTypeFile:
+		info, err := os.Stat(s)
+		if err != nil {
+			return nil, fmt.Errorf("path not found: %s", s)
+		}
+		if vt == ValueTypeDir && !info.IsDir() {
+			return nil, fmt.Errorf("path is not a directory: %s", s)
+		}
+		if vt == ValueTypeFile && info.IsDir() {
+			return nil, fmt.Errorf("path is a directory: %s", s)
+		}
+		return s, nil
+	case ValueTypeDuration:
+		return time.ParseDuration(s)
+	case ValueTypeFloat:
+		return strconv.ParseFloat(s, 64)
+	case ValueTypeHostPort:
+		host, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host:port: %s", s)
+		}
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 || host == "" {
+			return nil, fmt.Errorf("invalid host:port: %s", s)
+		}
+		return s, nil
+	case ValueTypeInteger:
+		return strconv.ParseInt(s, 10, 64)
+	case ValueTypeIP:
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid ip: %s", s)
+		}
+		return ip, nil
+	case ValueTypeSize:
+		return ParseSize(s)
+	case ValueTypeTime:
+		return time.Parse(time.RFC3339, s)
+	case ValueTypeURL:
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid url: %s", s)
+		}
+		return u, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,138 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-01-21
+
+package managers
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, x := range []struct {
+		Input  string
+		Expect int64
+		Range  bool
+		Failed bool
+	}{
+		{Input: "0", Expect: 0},
+		{Input: "512", Expect: 512},
+		{Input: " 512 B ", Expect: 512},
+		{Input: "1k", Expect: 1024},
+		{Input: "10MB", Expect: 10000000},
+		{Input: "10mb", Expect: 10000000},
+		{Input: "1.5GiB", Expect: 1610612736},
+		{Input: "2T", Expect: 2 << 40},
+		{Input: "1TB", Expect: 1000000000000},
+		{Input: "9223372036854775807", Expect: 9223372036854775807},
+		{Input: "8388607T", Expect: 8388607 << 40},
+
+		// Overflow.
+		{Input: "9223372036854775808", Range: true},
+		{Input: "9999999999T", Range: true},
+		{Input: "8388608T", Range: true},
+		{Input: "8388608.5T", Range: true},
+		{Input: "99999999999999999999.5", Range: true},
+
+		// Invalid.
+		{Input: "", Failed: true},
+		{Input: "-1", Failed: true},
+		{Input: "1.", Failed: true},
+		{Input: "10XB", Failed: true},
+		{Input: "MB", Failed: true},
+	} {
+		n, err := ParseSize(x.Input)
+		switch {
+		case x.Range:
+			if !errors.Is(err, strconv.ErrRange) {
+				t.Errorf("size %q: range error expected, got: %d, %v", x.Input, n, err)
+			}
+		case x.Failed:
+			if err == nil || errors.Is(err, strconv.ErrRange) {
+				t.Errorf("size %q: invalid error expected, got: %d, %v", x.Input, n, err)
+			}
+		case err != nil || n != x.Expect:
+			t.Errorf("size %q: %d, expect: %d, error: %v", x.Input, n, x.Expect, err)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, "file.txt")
+	)
+	if err := os.WriteFile(file, []byte("file"), os.ModePerm); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	for _, x := range []struct {
+		Type   ValueType
+		Input  string
+		Expect interface{}
+		Failed bool
+	}{
+		{Type: ValueTypeString, Input: "text", Expect: "text"},
+		{Type: ValueTypeBoolean, Input: "true", Expect: true},
+		{Type: ValueTypeBoolean, Input: "yes", Failed: true},
+		{Type: ValueTypeDuration, Input: "1m30s", Expect: 90 * time.Second},
+		{Type: ValueTypeDuration, Input: "30", Failed: true},
+		{Type: ValueTypeFloat, Input: "1.5", Expect: 1.5},
+		{Type: ValueTypeInteger, Input: "42", Expect: int64(42)},
+		{Type: ValueTypeInteger, Input: "4.2", Failed: true},
+		{Type: ValueTypeSize, Input: "1KiB", Expect: int64(1024)},
+		{Type: ValueTypeSize, Input: "9999999999T", Failed: true},
+		{Type: ValueTypeTime, Input: "2023-01-21T10:00:00Z", Expect: time.Date(2023, 1, 21, 10, 0, 0, 0, time.UTC)},
+		{Type: ValueTypeTime, Input: "2023-01-21", Failed: true},
+		{Type: ValueTypeHostPort, Input: "127.0.0.1:8500", Expect: "127.0.0.1:8500"},
+		{Type: ValueTypeHostPort, Input: "127.0.0.1", Failed: true},
+		{Type: ValueTypeHostPort, Input: ":8500", Failed: true},
+		{Type: ValueTypeHostPort, Input: "127.0.0.1:0", Failed: true},
+		{Type: ValueTypeHostPort, Input: "127.0.0.1:65536", Failed: true},
+		{Type: ValueTypeIP, Input: "::1", Expect: "::1"},
+		{Type: ValueTypeIP, Input: "10.0.0.256", Failed: true},
+		{Type: ValueTypeCIDR, Input: "10.0.0.1/8", Expect: "10.0.0.0/8"},
+		{Type: ValueTypeCIDR, Input: "10.0.0.1", Failed: true},
+		{Type: ValueTypeURL, Input: "https://example.com/path", Expect: "https://example.com/path"},
+		{Type: ValueTypeURL, Input: "example.com/path", Failed: true},
+		{Type: ValueTypeFile, Input: file, Expect: file},
+		{Type: ValueTypeFile, Input: dir, Failed: true},
+		{Type: ValueTypeFile, Input: filepath.Join(dir, "none"), Failed: true},
+		{Type: ValueTypeDir, Input: dir, Expect: dir},
+		{Type: ValueTypeDir, Input: file, Failed: true},
+	} {
+		v, err := ParseValue(x.Type, x.Input)
+		if x.Failed {
+			if err == nil {
+				t.Errorf("value %q of type %d: error expected, got: %v", x.Input, x.Type, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("value %q of type %d: %v", x.Input, x.Type, err)
+			continue
+		}
+
+		// Compare text
+		// of ip, cidr and url.
+		switch tv := v.(type) {
+		case net.IP:
+			v = tv.String()
+		case *net.IPNet:
+			v = tv.String()
+		case *url.URL:
+			v = tv.String()
+		case time.Time:
+			v, x.Expect = tv.UnixNano(), x.Expect.(time.Time).UnixNano()
+		}
+		if v != x.Expect {
+			t.Errorf("value %q of type %d: %v, expect: %v", x.Input, x.Type, v, x.Expect)
+		}
+	}
+}